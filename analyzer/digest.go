@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Изменение цены предмета за период
+type PriceMove struct {
+	ItemID       int     `json:"item_id"`
+	MarketName   string  `json:"market_name"`
+	Category     string  `json:"category"`
+	CurrentPrice float64 `json:"current_price"`
+	Change       float64 `json:"change"` // изменение цены, %
+}
+
+// Лидеры роста и падения в категории
+type CategoryMovers struct {
+	Gainers []PriceMove `json:"gainers"` // по убыванию роста
+	Losers  []PriceMove `json:"losers"`  // по убыванию падения
+}
+
+// Лидеры роста и падения по категориям с момента since (до limit в каждую сторону)
+func (ta *TrendAnalyzer) GetCategoryMovers(since time.Time, limit int) (map[string]*CategoryMovers, error) {
+	// Хранятся только изменения цены, поэтому начальная цена — последняя
+	// записанная не позже since; без нее берется первая цена окна
+	query := `WITH changes AS (
+				  SELECT w.item_id,
+				  COALESCE(
+					  (SELECT b.price FROM price_history b
+					   WHERE b.item_id = w.item_id AND b.recorded_at <= $1
+					   ORDER BY b.recorded_at DESC LIMIT 1),
+					  (ARRAY_AGG(w.price ORDER BY w.recorded_at ASC))[1]
+				  ) AS first_price,
+				  (ARRAY_AGG(w.price ORDER BY w.recorded_at DESC))[1] AS last_price
+				  FROM price_history w
+				  WHERE w.recorded_at > $1
+				  GROUP BY w.item_id
+			  ), ranked AS (
+				  SELECT i.id, i.market_name, i.category, c.last_price,
+				  (c.last_price - c.first_price) / c.first_price * 100 AS change
+				  FROM changes c
+				  JOIN items i ON i.id = c.item_id
+				  WHERE c.first_price > 0 AND c.last_price <> c.first_price
+			  ), ordered AS (
+				  SELECT *,
+				  ROW_NUMBER() OVER (PARTITION BY category ORDER BY change DESC) AS up_rank,
+				  ROW_NUMBER() OVER (PARTITION BY category ORDER BY change ASC) AS down_rank
+				  FROM ranked
+			  )
+			  SELECT id, market_name, category, last_price, change
+			  FROM ordered
+			  WHERE (up_rank <= $2 AND change > 0) OR (down_rank <= $2 AND change < 0)
+			  ORDER BY category, change DESC`
+
+	rows, err := ta.db.Query(query, since.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	movers := make(map[string]*CategoryMovers)
+	for rows.Next() {
+		var move PriceMove
+		if err := rows.Scan(&move.ItemID, &move.MarketName, &move.Category,
+			&move.CurrentPrice, &move.Change); err != nil {
+			continue
+		}
+
+		m, ok := movers[move.Category]
+		if !ok {
+			m = &CategoryMovers{}
+			movers[move.Category] = m
+		}
+		if move.Change > 0 {
+			m.Gainers = append(m.Gainers, move)
+		} else {
+			// Строки идут по убыванию изменения, поэтому сильнейшее падение — в начало
+			m.Losers = append([]PriceMove{move}, m.Losers...)
+		}
+	}
+
+	return movers, rows.Err()
+}
+
+// Текущие рекомендации BUY по убыванию рейтинга
+func (ta *TrendAnalyzer) GetBuyRecommendations(limit int) ([]ItemTrend, error) {
+	query := `SELECT ia.item_id, i.hash_name, i.market_name, i.category, i.image_url,
+			  ia.growth_rate, ia.volatility, ia.trend_score, ia.recommendation,
+			  (SELECT price FROM price_history WHERE item_id = ia.item_id ORDER BY recorded_at DESC LIMIT 1) as current_price
+			  FROM item_analysis ia
+			  JOIN items i ON ia.item_id = i.id
+			  WHERE ia.recommendation = 'BUY'
+			  ORDER BY ia.trend_score DESC, ia.growth_rate DESC
+			  LIMIT $1`
+
+	rows, err := ta.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trends []ItemTrend
+	for rows.Next() {
+		var trend ItemTrend
+		var currentPrice sql.NullFloat64
+
+		err := rows.Scan(&trend.ItemID, &trend.HashName, &trend.MarketName,
+			&trend.Category, &trend.ImageURL, &trend.GrowthRate, &trend.Volatility,
+			&trend.TrendScore, &trend.Recommendation, &currentPrice)
+		if err != nil {
+			continue
+		}
+
+		if currentPrice.Valid {
+			trend.CurrentPrice = currentPrice.Float64
+			trend.Price = currentPrice.Float64
+		}
+
+		trends = append(trends, trend)
+	}
+
+	return trends, rows.Err()
+}
@@ -1,16 +1,21 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"time"
 
+	"buff-youpin-checker/config"
 	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/metrics"
 	"database/sql"
 )
 
 type TrendAnalyzer struct {
-	db *database.DB
+	db     *database.DB
+	params *config.ParamsStore
 }
 
 type ItemTrend struct {
@@ -25,76 +30,140 @@ type ItemTrend struct {
 	TrendScore     int     `json:"trend_score"`     // рейтинг от 1 до 10
 	Recommendation string  `json:"recommendation"`  // BUY/HOLD/SELL
 	PredictedGrowth float64 `json:"predicted_growth"` // прогнозируемый рост
-	ExpectedROI    float64 `json:"expected_roi"`    // ожидаемый ROI (множитель)
 	Price          float64 `json:"price"`           // цена для расчетов
+	WeekChange     float64 `json:"week_change"`     // изменение цены за 7 дней, %
+
+	Risk []database.ItemRisk `json:"risk,omitempty"` // метрики риска по окнам анализа
+}
+
+func NewTrendAnalyzer(db *database.DB, params *config.ParamsStore) *TrendAnalyzer {
+	return &TrendAnalyzer{db: db, params: params}
 }
 
-func NewTrendAnalyzer(db *database.DB) *TrendAnalyzer {
-	return &TrendAnalyzer{db: db}
+// Анализ трендов для всех предметов; progress (может быть nil)
+// получает число обработанных предметов из общего количества.
+// При отмене ctx анализ останавливается после текущего предмета.
+// Каждый запуск вместе с действовавшими параметрами пишется в analysis_runs.
+func (ta *TrendAnalyzer) AnalyzeAllItems(ctx context.Context, progress func(done, total int)) error {
+	// Один набор параметров на весь запуск, даже если их перезагрузят в процессе
+	params := ta.params.Get()
+
+	started := time.Now()
+	counts, err := ta.analyzeAll(ctx, params.Analysis, progress)
+	finished := time.Now()
+
+	errText := ""
+	if err != nil {
+		errText = err.Error()
+	}
+	analyzed := counts["BUY"] + counts["HOLD"] + counts["SELL"]
+	if recErr := ta.db.RecordAnalysisRun(started, finished, analyzed, params.String(), errText); recErr != nil {
+		i18n.Logf("log.analysis.record_failed", recErr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	metrics.AnalysisDuration.Observe(finished.Sub(started).Seconds())
+	for recommendation, count := range counts {
+		metrics.AnalyzedItems.WithLabelValues(recommendation).Set(float64(count))
+	}
+	return nil
 }
 
-// Анализ трендов для всех предметов
-func (ta *TrendAnalyzer) AnalyzeAllItems() error {
+// Проход по всем предметам с историей цен; возвращает число предметов
+// по рекомендациям
+func (ta *TrendAnalyzer) analyzeAll(ctx context.Context, params config.AnalysisConfig, progress func(done, total int)) (map[string]int, error) {
+	counts := map[string]int{"BUY": 0, "HOLD": 0, "SELL": 0}
+
 	// Получаем все предметы с историей цен
-	query := `SELECT DISTINCT i.id, i.hash_name, i.market_name 
+	query := `SELECT DISTINCT i.id, i.hash_name, i.market_name, i.last_seen_at
 			  FROM items i 
 			  INNER JOIN price_history ph ON i.id = ph.item_id`
-	
-	rows, err := ta.db.Query(query)
+
+	rows, err := ta.db.QueryContext(ctx, query)
 	if err != nil {
-		return err
+		return counts, err
+	}
+
+	type itemRef struct {
+		id                   int
+		hashName, marketName string
+		lastSeen             sql.NullTime
 	}
-	defer rows.Close()
 
+	// Сначала читаем список целиком, чтобы знать общее количество
+	// и не держать курсор открытым во время записи результатов
+	var items []itemRef
 	for rows.Next() {
-		var itemID int
-		var hashName, marketName string
-		
-		if err := rows.Scan(&itemID, &hashName, &marketName); err != nil {
+		var item itemRef
+		if err := rows.Scan(&item.id, &item.hashName, &item.marketName, &item.lastSeen); err != nil {
 			continue
 		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return counts, err
+	}
 
-		trend, err := ta.analyzeItemTrend(itemID, hashName, marketName)
-		if err != nil {
-			continue
+	// Снимки прайс-листа за окно анализа отличают неизменившуюся цену
+	// от отсутствия предмета в прайс-листе
+	since := time.Now().AddDate(0, 0, -params.HistoryDays)
+	snapshots, err := ta.db.GetSnapshots(since)
+	if err != nil {
+		return counts, err
+	}
+
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			return counts, err
 		}
 
-		// Сохраняем результат анализа
-		ta.saveAnalysis(trend)
+		trend, err := ta.analyzeItemTrend(params, since, snapshots, item.id, item.hashName, item.marketName, item.lastSeen.Time)
+		if err == nil {
+			// Сохраняем результат анализа
+			ta.saveAnalysis(trend)
+			counts[trend.Recommendation]++
+		}
+
+		if progress != nil {
+			progress(i+1, len(items))
+		}
 	}
 
-	return nil
+	return counts, nil
 }
 
-// Анализ тренда для конкретного предмета
-func (ta *TrendAnalyzer) analyzeItemTrend(itemID int, hashName, marketName string) (*ItemTrend, error) {
-	// Получаем историю цен за последние 30 дней
-	query := `SELECT price, recorded_at FROM price_history 
+// Анализ тренда для конкретного предмета по истории цен начиная с since
+func (ta *TrendAnalyzer) analyzeItemTrend(params config.AnalysisConfig, since time.Time, snapshots []database.PriceSnapshot,
+	itemID int, hashName, marketName string, lastSeen time.Time) (*ItemTrend, error) {
+	presence, err := ta.db.GetItemPresence(itemID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT price, recorded_at, COALESCE(snapshot_id, 0) FROM price_history 
 			  WHERE item_id = $1 AND recorded_at >= $2 
 			  ORDER BY recorded_at ASC`
 	
-	thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
-	rows, err := ta.db.Query(query, itemID, thirtyDaysAgo)
+	rows, err := ta.db.Query(query, itemID, since.UTC())
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prices []float64
-	var timestamps []time.Time
-	
+	var points []pricePoint
 	for rows.Next() {
-		var price float64
-		var timestamp time.Time
-		
-		if err := rows.Scan(&price, &timestamp); err != nil {
+		var p pricePoint
+		if err := rows.Scan(&p.price, &p.recordedAt, &p.snapshotID); err != nil {
 			continue
 		}
-		
-		prices = append(prices, price)
-		timestamps = append(timestamps, timestamp)
+		points = append(points, p)
 	}
 
+	prices, timestamps := priceSeries(points, snapshots, presence, lastSeen)
 	if len(prices) < 1 {
 		return nil, fmt.Errorf("insufficient price data")
 	}
@@ -117,7 +186,7 @@ func (ta *TrendAnalyzer) analyzeItemTrend(itemID int, hashName, marketName strin
 	}
 	volatility := ta.calculateVolatility(prices)
 	trendScore := ta.calculateTrendScore(growthRate, volatility, len(prices))
-	recommendation := ta.getRecommendation(trendScore, growthRate)
+	recommendation := ta.getRecommendation(params, trendScore, growthRate)
 	predictedGrowth := ta.predictGrowth(prices, timestamps)
 
 	return &ItemTrend{
@@ -130,6 +199,7 @@ func (ta *TrendAnalyzer) analyzeItemTrend(itemID int, hashName, marketName strin
 		TrendScore:      trendScore,
 		Recommendation:  recommendation,
 		PredictedGrowth: predictedGrowth,
+		Risk:            computeRisk(params, prices, timestamps, time.Now()),
 	}, nil
 }
 
@@ -191,10 +261,10 @@ func (ta *TrendAnalyzer) calculateTrendScore(growthRate, volatility float64, dat
 }
 
 // Получение рекомендации
-func (ta *TrendAnalyzer) getRecommendation(trendScore int, growthRate float64) string {
-	if trendScore >= 8 && growthRate > 5 {
+func (ta *TrendAnalyzer) getRecommendation(params config.AnalysisConfig, trendScore int, growthRate float64) string {
+	if trendScore >= params.BuyMinScore && growthRate > params.BuyMinGrowth {
 		return "BUY"
-	} else if trendScore >= 6 {
+	} else if trendScore >= params.HoldMinScore {
 		return "HOLD"
 	} else {
 		return "SELL"
@@ -239,7 +309,10 @@ func (ta *TrendAnalyzer) saveAnalysis(trend *ItemTrend) error {
 			  
 	_, err := ta.db.Exec(query, trend.ItemID, trend.GrowthRate, 
 		trend.Volatility, trend.TrendScore, trend.Recommendation)
-	return err
+	if err != nil {
+		return err
+	}
+	return ta.db.SaveItemRisk(trend.ItemID, trend.Risk)
 }
 
 // Получение топовых предметов по рейтингу
@@ -249,11 +322,11 @@ func (ta *TrendAnalyzer) GetTopItems(limit int) ([]ItemTrend, error) {
 			  (SELECT price FROM price_history WHERE item_id = ia.item_id ORDER BY recorded_at DESC LIMIT 1) as current_price
 			  FROM item_analysis ia
 			  JOIN items i ON ia.item_id = i.id
-			  WHERE ia.trend_score >= 6
+			  WHERE ia.trend_score >= $2
 			  ORDER BY ia.trend_score DESC, ia.growth_rate DESC
 			  LIMIT $1`
 
-	rows, err := ta.db.Query(query, limit)
+	rows, err := ta.db.Query(query, limit, ta.params.Get().Analysis.TopMinScore)
 	if err != nil {
 		return nil, err
 	}
@@ -282,20 +355,21 @@ func (ta *TrendAnalyzer) GetTopItems(limit int) ([]ItemTrend, error) {
 	return trends, nil
 }
 
-// Получить лучшие предметы для инвестиций с минимальным ROI
-func (ta *TrendAnalyzer) GetBestInvestmentItems(limit int, minROI float64) ([]ItemTrend, error) {
+// Кандидаты для калькулятора бюджета: предметы с рекомендацией BUY.
+// Порог чистого ROI применяет оптимизатор: доходность за срок владения
+// и издержки продажи зависят от дневной истории и числа штук.
+func (ta *TrendAnalyzer) GetBestInvestmentItems(limit int) ([]ItemTrend, error) {
 	query := `SELECT ia.item_id, i.hash_name, i.market_name, i.category, i.image_url,
 			  ia.growth_rate, ia.volatility, ia.trend_score, ia.recommendation,
 			  (SELECT price FROM price_history WHERE item_id = ia.item_id ORDER BY recorded_at DESC LIMIT 1) as current_price
 			  FROM item_analysis ia
 			  JOIN items i ON ia.item_id = i.id
-			  WHERE ia.trend_score >= 6 
+			  WHERE ia.trend_score >= $2
 			    AND ia.recommendation = 'BUY'
-			    AND (1 + ia.growth_rate/100.0) >= $1
 			  ORDER BY ia.trend_score DESC, ia.growth_rate DESC
-			  LIMIT $2`
+			  LIMIT $1`
 
-	rows, err := ta.db.Query(query, minROI, limit)
+	rows, err := ta.db.Query(query, limit, ta.params.Get().Analysis.TopMinScore)
 	if err != nil {
 		return nil, err
 	}
@@ -305,34 +379,31 @@ func (ta *TrendAnalyzer) GetBestInvestmentItems(limit int, minROI float64) ([]It
 	for rows.Next() {
 		var trend ItemTrend
 		var currentPrice sql.NullFloat64
-		
-		err := rows.Scan(&trend.ItemID, &trend.HashName, &trend.MarketName, 
-			&trend.Category, &trend.ImageURL, &trend.GrowthRate, &trend.Volatility, 
+
+		err := rows.Scan(&trend.ItemID, &trend.HashName, &trend.MarketName,
+			&trend.Category, &trend.ImageURL, &trend.GrowthRate, &trend.Volatility,
 			&trend.TrendScore, &trend.Recommendation, &currentPrice)
 		if err != nil {
 			continue
 		}
-		
+
 		if currentPrice.Valid {
 			trend.CurrentPrice = currentPrice.Float64
-			trend.Price = currentPrice.Float64  // Для расчетов бюджета
+			trend.Price = currentPrice.Float64 // Для расчетов бюджета
 		}
-		
-		// Рассчитываем ожидаемый ROI
-		trend.ExpectedROI = 1 + (trend.GrowthRate / 100.0)
-		
+
 		trends = append(trends, trend)
 	}
 
-	return trends, nil
+	return trends, rows.Err()
 }
 
 func (ta *TrendAnalyzer) GetTopItemsByCategory(category string, limit int) ([]ItemTrend, error) {
-	// По умолчанию используем минимальный порог рейтинга 6,
-	// но для стикеров снижаем порог до 4, чтобы избегать пустых выборок
-	minScore := 6
+	// Для стикеров порог ниже, чтобы избегать пустых выборок
+	params := ta.params.Get().Analysis
+	minScore := params.TopMinScore
 	if category == "stickers" {
-		minScore = 4
+		minScore = params.StickerMinScore
 	}
 	return ta.GetTopItemsByCategoryMinScore(category, minScore, limit)
 }
@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"buff-youpin-checker/config"
+	"buff-youpin-checker/database"
+)
+
+// Меньше дневных доходностей — оценки риска не имеют смысла
+const minRiskObservations = 5
+
+// Рынок скинов работает без выходных
+const tradingDaysPerYear = 365
+
+// Метрики риска по окнам params.RiskWindows. Ряд сводится к ценам
+// закрытия по дням (UTC); окна, где дневных доходностей меньше
+// minRiskObservations, пропускаются.
+func computeRisk(params config.AnalysisConfig, prices []float64, timestamps []time.Time, now time.Time) []database.ItemRisk {
+	closes, days := dailyCloses(prices, timestamps)
+
+	var risks []database.ItemRisk
+	for _, window := range params.RiskWindows {
+		from := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -window)
+		start := sort.Search(len(days), func(i int) bool { return !days[i].Before(from) })
+		series := closes[start:]
+
+		returns := logReturns(series)
+		if len(returns) < minRiskObservations {
+			continue
+		}
+
+		risk := database.ItemRisk{
+			WindowDays:   window,
+			Observations: len(returns),
+			Confidence:   params.RiskConfidence,
+			MaxDrawdown:  maxDrawdown(series) * 100,
+			ComputedAt:   now,
+		}
+
+		mean, std := meanStd(returns)
+		risk.Volatility = std * math.Sqrt(tradingDaysPerYear) * 100
+
+		// Исторический VaR: квантиль распределения доходностей; CVaR —
+		// среднее по дням хуже него. Оба выражены как процент убытка.
+		sorted := append([]float64(nil), returns...)
+		sort.Float64s(sorted)
+		tail := int(math.Ceil(float64(len(sorted)) * (1 - params.RiskConfidence)))
+		if tail < 1 {
+			tail = 1
+		}
+		cutoff := sorted[tail-1]
+		sum := 0.0
+		for _, r := range sorted[:tail] {
+			sum += r
+		}
+		risk.VaR = lossPercent(cutoff)
+		risk.CVaR = lossPercent(sum / float64(tail))
+
+		// Коэффициенты в годовом выражении относительно безрисковой ставки
+		riskFree := math.Log(1+params.RiskFreeRate/100) / tradingDaysPerYear
+		excess := mean - riskFree
+		if std > 0 {
+			risk.Sharpe = excess / std * math.Sqrt(tradingDaysPerYear)
+		}
+		if downside := downsideDeviation(returns, riskFree); downside > 0 {
+			risk.Sortino = excess / downside * math.Sqrt(tradingDaysPerYear)
+		}
+
+		risks = append(risks, risk)
+	}
+	return risks
+}
+
+// Последняя цена каждого дня (UTC) и начало этого дня
+func dailyCloses(prices []float64, timestamps []time.Time) ([]float64, []time.Time) {
+	var closes []float64
+	var days []time.Time
+	for i, price := range prices {
+		day := timestamps[i].UTC().Truncate(24 * time.Hour)
+		if n := len(days); n > 0 && days[n-1].Equal(day) {
+			closes[n-1] = price
+			continue
+		}
+		closes = append(closes, price)
+		days = append(days, day)
+	}
+	return closes, days
+}
+
+func logReturns(prices []float64) []float64 {
+	var returns []float64
+	for i := 1; i < len(prices); i++ {
+		if prices[i-1] > 0 && prices[i] > 0 {
+			returns = append(returns, math.Log(prices[i]/prices[i-1]))
+		}
+	}
+	return returns
+}
+
+// Среднее и выборочное стандартное отклонение
+func meanStd(values []float64) (float64, float64) {
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)-1))
+}
+
+// Отклонение доходностей ниже целевой доходности target
+func downsideDeviation(returns []float64, target float64) float64 {
+	sum := 0.0
+	for _, r := range returns {
+		if d := r - target; d < 0 {
+			sum += d * d
+		}
+	}
+	return math.Sqrt(sum / float64(len(returns)))
+}
+
+// Максимальная просадка от предыдущего пика, доля
+func maxDrawdown(prices []float64) float64 {
+	peak, worst := 0.0, 0.0
+	for _, p := range prices {
+		if p > peak {
+			peak = p
+		}
+		if peak > 0 {
+			if dd := (peak - p) / peak; dd > worst {
+				worst = dd
+			}
+		}
+	}
+	return worst
+}
+
+// Лог-доходность в процент убытка; прибыль дает 0
+func lossPercent(logReturn float64) float64 {
+	return math.Max(0, -math.Expm1(logReturn)*100)
+}
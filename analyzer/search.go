@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Поиск предметов по названию с данными тренда и изменением за 7 дней.
+// Предметы без анализа тоже возвращаются (TrendScore = 0, Recommendation пустая).
+func (ta *TrendAnalyzer) SearchItemTrends(text string, limit, offset int) ([]ItemTrend, int, error) {
+	results, total, err := ta.db.SearchItems(text, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	trends := make([]ItemTrend, 0, len(results))
+	ids := make([]int, 0, len(results))
+	for _, r := range results {
+		trends = append(trends, ItemTrend{
+			ItemID:         r.ItemID,
+			MarketName:     r.MarketName,
+			Category:       r.Category,
+			CurrentPrice:   r.CurrentPrice,
+			Price:          r.CurrentPrice,
+			TrendScore:     r.TrendScore,
+			Recommendation: r.Recommendation,
+		})
+		ids = append(ids, r.ItemID)
+	}
+
+	changes, err := ta.getPriceChanges(ids, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range trends {
+		trends[i].WeekChange = changes[trends[i].ItemID]
+	}
+
+	return trends, total, nil
+}
+
+// Изменение цены в процентах: последняя цена относительно первой цены после since
+func (ta *TrendAnalyzer) getPriceChanges(itemIDs []int, since time.Time) (map[int]float64, error) {
+	query := `SELECT item_id,
+			  (ARRAY_AGG(price ORDER BY recorded_at ASC))[1] AS first_price,
+			  (ARRAY_AGG(price ORDER BY recorded_at DESC))[1] AS last_price
+			  FROM price_history
+			  WHERE item_id = ANY($1) AND recorded_at >= $2
+			  GROUP BY item_id`
+
+	rows, err := ta.db.Query(query, pq.Array(itemIDs), since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make(map[int]float64, len(itemIDs))
+	for rows.Next() {
+		var itemID int
+		var firstPrice, lastPrice float64
+		if err := rows.Scan(&itemID, &firstPrice, &lastPrice); err != nil {
+			continue
+		}
+		if firstPrice > 0 {
+			changes[itemID] = (lastPrice - firstPrice) / firstPrice * 100
+		}
+	}
+
+	return changes, rows.Err()
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+// Строка истории цен
+type pricePoint struct {
+	price      float64
+	recordedAt time.Time
+	snapshotID int // 0 — строка записана до появления снимков
+}
+
+// Ряд цен по снимкам прайс-листа. В снимке, где записываются только
+// изменения, предмет без строки считается неизменившимся, если его
+// последняя строка моложе heartbeat и предмет был в этом снимке; иначе
+// точка пропускается. Состав снимка берется из серий присутствия, а для
+// снимков, записанных до них, — по lastSeen. Строки до первого снимка
+// берутся как есть.
+func priceSeries(points []pricePoint, snapshots []database.PriceSnapshot, presence []database.Presence, lastSeen time.Time) ([]float64, []time.Time) {
+	var prices []float64
+	var timestamps []time.Time
+
+	j := 0
+	if len(snapshots) > 0 {
+		for ; j < len(points) && points[j].recordedAt.Before(snapshots[0].FetchedAt); j++ {
+			prices = append(prices, points[j].price)
+			timestamps = append(timestamps, points[j].recordedAt)
+		}
+	} else {
+		for ; j < len(points); j++ {
+			prices = append(prices, points[j].price)
+			timestamps = append(timestamps, points[j].recordedAt)
+		}
+	}
+
+	var last *pricePoint
+	if j > 0 {
+		last = &points[j-1]
+	}
+	for _, s := range snapshots {
+		for ; j < len(points) && !points[j].recordedAt.After(s.FetchedAt); j++ {
+			last = &points[j]
+		}
+		if last == nil {
+			continue
+		}
+
+		present := last.snapshotID == s.ID
+		if !present && s.Heartbeat > 0 {
+			present = s.FetchedAt.Sub(last.recordedAt) < s.Heartbeat && inSnapshot(s, presence, lastSeen)
+		}
+		if present {
+			prices = append(prices, last.price)
+			timestamps = append(timestamps, s.FetchedAt)
+		}
+	}
+	return prices, timestamps
+}
+
+// Был ли предмет в снимке s
+func inSnapshot(s database.PriceSnapshot, presence []database.Presence, lastSeen time.Time) bool {
+	if !s.Presence {
+		return !lastSeen.Before(s.FetchedAt)
+	}
+	for _, p := range presence {
+		if p.Contains(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+func hour(h int) time.Time {
+	return time.Date(2024, 3, 1, h, 0, 0, 0, time.UTC)
+}
+
+func TestPriceSeriesPresence(t *testing.T) {
+	snapshots := []database.PriceSnapshot{
+		{ID: 1, Source: "csqaq", FetchedAt: hour(1), Heartbeat: 24 * time.Hour, Presence: true},
+		{ID: 2, Source: "csqaq", FetchedAt: hour(2), Heartbeat: 24 * time.Hour, Presence: true},
+		{ID: 3, Source: "csqaq", FetchedAt: hour(3), Heartbeat: 24 * time.Hour, Presence: true},
+		{ID: 4, Source: "csqaq", FetchedAt: hour(4), Heartbeat: 24 * time.Hour, Presence: true},
+	}
+	points := []pricePoint{{price: 10, recordedAt: hour(1), snapshotID: 1}}
+
+	// Предмета не было в снимке 3, но сегодня он снова в прайс-листе
+	presence := []database.Presence{
+		{Source: "csqaq", FirstSeen: hour(1), LastSeen: hour(2)},
+		{Source: "csqaq", FirstSeen: hour(4), LastSeen: hour(4)},
+	}
+	_, timestamps := priceSeries(points, snapshots, presence, hour(4))
+	want := []time.Time{hour(1), hour(2), hour(4)}
+	if len(timestamps) != len(want) {
+		t.Fatalf("timestamps = %v, want %v", timestamps, want)
+	}
+	for i := range want {
+		if !timestamps[i].Equal(want[i]) {
+			t.Fatalf("timestamps = %v, want %v", timestamps, want)
+		}
+	}
+}
+
+func TestPriceSeriesLegacySnapshots(t *testing.T) {
+	// Снимки без серий присутствия: состав оценивается по lastSeen
+	snapshots := []database.PriceSnapshot{
+		{ID: 1, Source: "csqaq", FetchedAt: hour(1), Heartbeat: 24 * time.Hour},
+		{ID: 2, Source: "csqaq", FetchedAt: hour(2), Heartbeat: 24 * time.Hour},
+		{ID: 3, Source: "csqaq", FetchedAt: hour(3), Heartbeat: 24 * time.Hour},
+	}
+	points := []pricePoint{{price: 10, recordedAt: hour(1), snapshotID: 1}}
+
+	prices, _ := priceSeries(points, snapshots, nil, hour(2))
+	if len(prices) != 2 {
+		t.Fatalf("got %d points, want 2", len(prices))
+	}
+}
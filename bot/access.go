@@ -0,0 +1,278 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Политика доступа к боту
+type AccessPolicy struct {
+	Allowlist bool    // пускать только пользователей с ролью member или admin
+	Admins    []int64 // администраторы из конфигурации, роль в БД им не нужна
+}
+
+// Сколько пользователей показывать в /users
+const maxUsersListed = 30
+
+// Роль пользователя; пустая строка — пользователь неизвестен и в режиме
+// allowlist доступа не имеет. В открытом режиме новые пользователи
+// регистрируются как member.
+func (b *Bot) role(user *tgbotapi.User) string {
+	role, err := b.lookupRole(user.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if b.access.Allowlist {
+			return ""
+		}
+		role = database.RoleMember
+		if err := b.db.RegisterUser(user.ID, user.UserName, role); err != nil {
+			i18n.Logf("log.access.save_failed", user.ID, err)
+			return role
+		}
+		b.cacheRole(user.ID, role)
+	case err != nil:
+		i18n.Logf("log.access.load_failed", user.ID, err)
+		if b.access.Allowlist {
+			return ""
+		}
+		return database.RoleMember
+	}
+	return role
+}
+
+// Роль по ID: администраторы из конфигурации, кэш, затем БД.
+// sql.ErrNoRows если пользователь неизвестен.
+func (b *Bot) lookupRole(userID int64) (string, error) {
+	for _, id := range b.access.Admins {
+		if id == userID {
+			return database.RoleAdmin, nil
+		}
+	}
+
+	b.roleMu.RLock()
+	role, ok := b.roles[userID]
+	b.roleMu.RUnlock()
+	if ok {
+		return role, nil
+	}
+
+	// Ошибки БД не кэшируем: проверим еще раз на следующем апдейте
+	role, err := b.db.GetUserRole(userID)
+	if err != nil {
+		return "", err
+	}
+	b.cacheRole(userID, role)
+	return role, nil
+}
+
+func (b *Bot) cacheRole(userID int64, role string) {
+	b.roleMu.Lock()
+	b.roles[userID] = role
+	b.roleMu.Unlock()
+}
+
+func (b *Bot) isAdmin(userID int64) bool {
+	role, err := b.lookupRole(userID)
+	return err == nil && role == database.RoleAdmin
+}
+
+// Проверка доступа для апдейта; отказ сообщается пользователю
+func (b *Bot) authorize(update tgbotapi.Update) bool {
+	var user *tgbotapi.User
+	switch {
+	case update.Message != nil:
+		user = update.Message.From
+	case update.CallbackQuery != nil:
+		user = update.CallbackQuery.From
+	case update.InlineQuery != nil:
+		user = update.InlineQuery.From
+	}
+	if user == nil {
+		return true
+	}
+
+	role := b.role(user)
+	if role == database.RoleAdmin || role == database.RoleMember {
+		return true
+	}
+
+	// Заблокированным не отвечаем, неизвестным в режиме allowlist сообщаем их ID
+	lang := b.lang(user.ID)
+	switch {
+	case update.Message != nil && role == "":
+		b.send(tgbotapi.NewMessage(update.Message.Chat.ID, i18n.T(lang, "access.denied", user.ID)))
+	case update.CallbackQuery != nil:
+		b.request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, i18n.T(lang, "access.denied_short")))
+	}
+	return false
+}
+
+// Проверка прав администратора для команды
+func (b *Bot) requireAdmin(message *tgbotapi.Message) bool {
+	if message.From != nil && b.isAdmin(message.From.ID) {
+		return true
+	}
+	b.send(tgbotapi.NewMessage(message.Chat.ID, i18n.T(b.lang(message.Chat.ID), "access.admin_only")))
+	return false
+}
+
+// Обработка /stats
+func (b *Bot) sendStats(chatID int64) {
+	lang := b.lang(chatID)
+
+	stats, err := b.db.GetStats()
+	if err != nil {
+		i18n.Logf("log.access.stats_failed", err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.data_error")))
+		return
+	}
+
+	formatTime := func(t sql.NullTime) string {
+		if !t.Valid {
+			return i18n.T(lang, "stats.never")
+		}
+		return t.Time.Format("02.01.2006 15:04")
+	}
+
+	text := newMessage(lang).T("stats.title")
+	text.T("stats.rows", stats.Items, stats.PriceRows, stats.AnalyzedItems)
+	text.T("stats.last_price", formatTime(stats.LastPriceAt))
+	text.T("stats.last_analysis", formatTime(stats.LastAnalysisAt))
+
+	if run := stats.LastRun; run != nil {
+		status := i18n.T(lang, "stats.run_ok")
+		if run.Error != "" {
+			status = i18n.T(lang, "stats.run_failed")
+		}
+		text.T("stats.last_run", run.StartedAt.Format("02.01.2006 15:04"), status,
+			run.FinishedAt.Sub(run.StartedAt).Seconds(), run.ItemsReceived, run.ItemsStored, run.ItemsFailed)
+	} else {
+		text.T("stats.no_runs")
+	}
+
+	text.T("stats.failed_runs", stats.FailedRuns)
+	if stats.LastError != "" {
+		text.T("stats.last_error", formatTime(stats.LastErrorAt), stats.LastError)
+	}
+
+	if counts, err := b.db.CountUsersByRole(); err == nil {
+		text.T("stats.users", counts[database.RoleAdmin], counts[database.RoleMember], counts[database.RoleBlocked])
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = parseModeHTML
+	b.send(msg)
+}
+
+// Обработка /users [роль]
+func (b *Bot) sendUsers(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	role := strings.ToLower(strings.TrimSpace(args))
+	if role != "" && !validRole(role) {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "users.bad_role")))
+		return
+	}
+
+	users, err := b.db.GetUsers(role, maxUsersListed)
+	if err != nil {
+		i18n.Logf("log.access.load_failed", chatID, err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.data_error")))
+		return
+	}
+	if len(users) == 0 {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "users.empty")))
+		return
+	}
+
+	text := i18n.T(lang, "users.title")
+	for _, u := range users {
+		name := "—"
+		if u.Username != "" {
+			name = "@" + u.Username
+		}
+		text += i18n.T(lang, "users.line", u.UserID, name, u.Role, u.CreatedAt.Format("02.01.2006"))
+	}
+	text += i18n.T(lang, "users.hint")
+	b.send(tgbotapi.NewMessage(chatID, text))
+}
+
+// Обработка /setrole <ID|@имя> <admin|member|blocked>
+func (b *Bot) setUserRole(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	fields := strings.Fields(args)
+	if len(fields) != 2 {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "setrole.usage")))
+		return
+	}
+
+	role := strings.ToLower(fields[1])
+	if !validRole(role) {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "users.bad_role")))
+		return
+	}
+
+	userID, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		userID, err = b.db.FindUserByUsername(strings.TrimPrefix(fields[0], "@"))
+		if err != nil {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "setrole.unknown_user")))
+			return
+		}
+	}
+
+	for _, id := range b.access.Admins {
+		if id == userID {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "setrole.config_admin")))
+			return
+		}
+	}
+
+	if err := b.db.SetUserRole(userID, role); err != nil {
+		i18n.Logf("log.access.save_failed", userID, err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.try_later")))
+		return
+	}
+
+	b.cacheRole(userID, role)
+
+	i18n.Logf("log.access.role_changed", userID, role, chatID)
+	b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "setrole.done", userID, role)))
+}
+
+func validRole(role string) bool {
+	switch role {
+	case database.RoleAdmin, database.RoleMember, database.RoleBlocked:
+		return true
+	}
+	return false
+}
+
+// Обработка /reload: перечитать параметры анализа и бюджета
+func (b *Bot) reloadParams(chatID int64) {
+	lang := b.lang(chatID)
+
+	params, err := b.params.Reload()
+	if err != nil {
+		i18n.Logf("log.params.reload_failed", err)
+		text := newMessage(lang).T("reload.failed").Raw("<pre>" + escapeHTML(err.Error()) + "</pre>")
+		msg := tgbotapi.NewMessage(chatID, text.String())
+		msg.ParseMode = parseModeHTML
+		b.send(msg)
+		return
+	}
+
+	i18n.Logf("log.params.reloaded", chatID)
+	i18n.Logf("log.params.active", params)
+	text := newMessage(lang).T("reload.done").Raw("<pre>" + escapeHTML(params.String()) + "</pre>")
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = parseModeHTML
+	b.send(msg)
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"buff-youpin-checker/collector"
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/market"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Сколько администраторов из БД оповещать
+const maxAlertRecipients = 50
+
+// Оповещение администраторов о состоянии сбора цен
+func (b *Bot) AlertAdmins(alert collector.Alert) {
+	for _, adminID := range b.adminIDs() {
+		lang := b.lang(adminID)
+
+		var text string
+		switch {
+		case alert.Resolved:
+			text = i18n.T(lang, "alert.collect_recovered", alert.Source)
+		case alert.Kind == market.ErrAuth:
+			text = i18n.T(lang, "alert.collect_auth", alert.Source, alert.Message, alert.RetryAt.Format("15:04"))
+		default:
+			text = i18n.T(lang, "alert.collect_failing", alert.Source, alert.Failures, alert.Kind.String(),
+				alert.Message, alert.RetryAt.Format("15:04"))
+		}
+
+		b.send(tgbotapi.NewMessage(adminID, text))
+	}
+}
+
+// Администраторы из конфигурации и назначенные через /setrole
+func (b *Bot) adminIDs() []int64 {
+	seen := make(map[int64]bool)
+	var ids []int64
+	add := func(id int64) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, id := range b.access.Admins {
+		add(id)
+	}
+
+	users, err := b.db.GetUsers(database.RoleAdmin, maxAlertRecipients)
+	if err != nil {
+		i18n.Logf("log.access.admins_failed", err)
+	}
+	for _, u := range users {
+		add(u.UserID)
+	}
+	return ids
+}
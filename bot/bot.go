@@ -1,39 +1,89 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"strconv"
-	"strings"
+	"sort"
+	"sync"
+	"time"
 
 	"buff-youpin-checker/analyzer"
+	"buff-youpin-checker/chart"
+	"buff-youpin-checker/config"
 	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/jobs"
+	"buff-youpin-checker/metrics"
+	"buff-youpin-checker/optimizer"
+	"buff-youpin-checker/portfolio"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type Bot struct {
-	api      *tgbotapi.BotAPI
-	analyzer *analyzer.TrendAnalyzer
-	db       *database.DB
+	api       *tgbotapi.BotAPI
+	analyzer  *analyzer.TrendAnalyzer
+	db        *database.DB
+	portfolio *portfolio.Tracker
+	charts    *chart.ChartGenerator
+
+	searchMu sync.Mutex
+	searches map[int64]string // последний поисковый запрос в чате
+
+	langMu sync.RWMutex
+	langs  map[int64]i18n.Lang // кэш языков пользователей
+
+	callbacks *callbackRouter
+	outbox    *sender
+
+	jobs   *jobs.Coordinator
+	access AccessPolicy
+	params *config.ParamsStore
+	roleMu sync.RWMutex
+	roles  map[int64]string // кэш ролей пользователей
+
+	updates  chan tgbotapi.Update // очередь апдейтов для воркеров
+	workers  sync.WaitGroup
+	stopOnce sync.Once
+	stopping chan struct{} // закрывается в начале Shutdown
+
+	queueMu     sync.RWMutex // отправители держат RLock, Shutdown закрывает очередь под Lock
+	queueClosed bool
 }
 
-func NewBot(token string, analyzer *analyzer.TrendAnalyzer, db *database.DB) (*Bot, error) {
+func NewBot(token string, analyzer *analyzer.TrendAnalyzer, coordinator *jobs.Coordinator, db *database.DB, access AccessPolicy, params *config.ParamsStore) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, err
 	}
 
 	api.Debug = false
-	log.Printf("Бот авторизован как %s", api.Self.UserName)
-
-	return &Bot{
-		api:      api,
-		analyzer: analyzer,
-		db:       db,
-	}, nil
+	i18n.Logf("log.bot.authorized", api.Self.UserName)
+
+	b := &Bot{
+		api:       api,
+		analyzer:  analyzer,
+		db:        db,
+		portfolio: portfolio.NewTracker(db),
+		charts:    chart.NewChartGenerator(db),
+		searches:  make(map[int64]string),
+		langs:     make(map[int64]i18n.Lang),
+		outbox:    newSender(api, db),
+		jobs:      coordinator,
+		access:    access,
+		params:    params,
+		roles:     make(map[int64]string),
+		stopping:  make(chan struct{}),
+	}
+	b.registerCallbacks()
+
+	return b, nil
 }
 
-func (b *Bot) Start() {
+// Запуск в режиме long polling. Возвращается после отмены ctx,
+// когда воркеры обработают уже полученные апдейты, а очереди
+// исходящих сообщений будут отправлены (см. Shutdown).
+func (b *Bot) Start(ctx context.Context) {
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
@@ -41,156 +91,233 @@ func (b *Bot) Start() {
 	b.api.Request(tgbotapi.DeleteWebhookConfig{})
 
 	updates := b.api.GetUpdatesChan(u)
+	b.startWorkers()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.api.StopReceivingUpdates()
+			b.Shutdown()
+			return
+		case update, ok := <-updates:
+			if !ok {
+				b.Shutdown()
+				return
+			}
+			// После отмены ctx апдейт не ждет места в очереди
+			b.enqueue(ctx, update)
+		}
+	}
+}
+
+// Остановка обработки: новые апдейты не принимаются, воркеры дорабатывают
+// очередь, затем отправляются накопленные исходящие сообщения.
+// В режиме вебхука вызывается после остановки HTTP-сервера.
+func (b *Bot) Shutdown() {
+	b.stopOnce.Do(func() {
+		// Ожидающие места в очереди отправители выходят по stopping;
+		// после Lock новых отправок не будет и очередь можно закрыть
+		close(b.stopping)
+		b.queueMu.Lock()
+		b.queueClosed = true
+		if b.updates != nil {
+			close(b.updates)
+		}
+		b.queueMu.Unlock()
+		b.workers.Wait()
+		b.outbox.stop(sendDrainTimeout)
+		i18n.Logf("log.bot.stopped")
+	})
+}
 
-	// Воркер-пул для конкурентной обработки апдейтов
+// Постановка апдейта в очередь воркеров; false, если бот останавливается
+// или ctx отменен раньше, чем освободилось место
+func (b *Bot) enqueue(ctx context.Context, update tgbotapi.Update) bool {
+	b.queueMu.RLock()
+	defer b.queueMu.RUnlock()
+	if b.queueClosed {
+		return false
+	}
+
+	select {
+	case b.updates <- update:
+		return true
+	case <-b.stopping:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Воркер-пул для конкурентной обработки апдейтов
+func (b *Bot) startWorkers() {
 	workerCount := 8
-	jobs := make(chan tgbotapi.Update, 100)
+	b.updates = make(chan tgbotapi.Update, 100)
 
 	for i := 0; i < workerCount; i++ {
+		b.workers.Add(1)
 		go func() {
-			for update := range jobs {
-				if update.Message != nil {
-					b.handleMessage(update.Message)
-				} else if update.CallbackQuery != nil {
-					b.handleCallbackQuery(update.CallbackQuery)
-				}
+			defer b.workers.Done()
+			for update := range b.updates {
+				b.handleUpdate(update)
 			}
 		}()
 	}
+}
 
-	for update := range updates {
-		jobs <- update
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+	if !b.authorize(update) {
+		return
+	}
+
+	if update.Message != nil {
+		// Пользователь снова пишет — значит, сообщения можно доставлять
+		b.outbox.unblock(update.Message.Chat.ID)
+		b.handleMessage(update.Message)
+		metrics.UpdatesHandled.WithLabelValues("message").Inc()
+	} else if update.CallbackQuery != nil {
+		b.handleCallbackQuery(update.CallbackQuery)
+		metrics.UpdatesHandled.WithLabelValues("callback_query").Inc()
+	} else if update.InlineQuery != nil {
+		b.handleInlineQuery(update.InlineQuery)
+		metrics.UpdatesHandled.WithLabelValues("inline_query").Inc()
+	} else if update.MyChatMember != nil {
+		b.handleMyChatMember(update.MyChatMember)
+		metrics.UpdatesHandled.WithLabelValues("my_chat_member").Inc()
 	}
 }
 
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
+	lang := b.lang(message.Chat.ID)
+
+	if !message.IsCommand() {
+		b.handleText(message)
+		return
+	}
+
+	// Любая команда прерывает текущий диалог
+	b.clearState(message.Chat.ID)
+
 	switch message.Command() {
 	case "start":
 		b.sendWelcomeMessage(message.Chat.ID)
 	case "top":
-		b.sendTopItems(message.Chat.ID)
+		b.sendTopItems(message.Chat.ID, nil)
 	case "budget":
 		b.sendBudgetCalculator(message.Chat.ID)
 	case "analyze":
-		b.runAnalysis(message.Chat.ID)
-	default:
-		if message.IsCommand() {
-			msg := tgbotapi.NewMessage(message.Chat.ID, "Неизвестная команда. Используйте /start для помощи.")
-			b.api.Send(msg)
-		} else {
-			// Пробуем парсить как бюджет
-			if budget, err := strconv.ParseFloat(message.Text, 64); err == nil && budget > 0 {
-				if budget < 1000 {
-					msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Минимальный бюджет: 1000₽")
-					b.api.Send(msg)
-				} else if budget > 10000000 {
-					msg := tgbotapi.NewMessage(message.Chat.ID, "❌ Максимальный бюджет: 10,000,000₽")
-					b.api.Send(msg)
-				} else {
-					b.sendBudgetResults(message.Chat.ID, budget)
-				}
-			}
+		if b.requireAdmin(message) {
+			b.runAnalysis(message.Chat.ID)
+		}
+	case "collect":
+		if b.requireAdmin(message) {
+			b.runCollection(message.Chat.ID)
+		}
+	case "stats":
+		if b.requireAdmin(message) {
+			b.sendStats(message.Chat.ID)
 		}
+	case "users":
+		if b.requireAdmin(message) {
+			b.sendUsers(message.Chat.ID, message.CommandArguments())
+		}
+	case "setrole":
+		if b.requireAdmin(message) {
+			b.setUserRole(message.Chat.ID, message.CommandArguments())
+		}
+	case "reload":
+		if b.requireAdmin(message) {
+			b.reloadParams(message.Chat.ID)
+		}
+	case "jobs":
+		if b.requireAdmin(message) {
+			b.sendJobs(message.Chat.ID)
+		}
+	case "portfolio":
+		b.sendPortfolio(message.Chat.ID, message.From.ID)
+	case "buy":
+		b.startPortfolioTrade(message, "BUY")
+	case "sell":
+		b.startPortfolioTrade(message, "SELL")
+	case "search":
+		b.startSearch(message.Chat.ID, message.CommandArguments())
+	case "subscribe":
+		b.handleSubscribe(message.Chat.ID, message.CommandArguments())
+	case "unsubscribe":
+		b.handleUnsubscribe(message.Chat.ID)
+	case "language":
+		b.sendLanguageMenu(message.Chat.ID)
+	default:
+		msg := tgbotapi.NewMessage(message.Chat.ID, i18n.T(lang, "common.unknown_command"))
+		b.send(msg)
 	}
 }
 
 func (b *Bot) sendWelcomeMessage(chatID int64) {
-	text := `🎮 *Добро пожаловать в CS2 Skin Analyzer!*
-
-Этот бот поможет вам найти перспективные скины для инвестиций.
-
-📊 *Доступные команды:*
-/top - Топ перспективных скинов (с пагинацией)
-/budget - Рассчитать оптимальный портфель инвестиций
-/analyze - Запустить анализ рынка
-
-🚀 *Как это работает:*
-Бот анализирует ценовые тренды скинов и выдает рейтинг от 1 до 10, где 10 - максимально перспективный предмет для покупки.
-
-💡 *Рекомендации:*
-• 🟢 BUY - рекомендуется к покупке
-• 🟡 HOLD - держать если есть  
-• 🔴 SELL - рекомендуется продать
-
-🔍 *Навигация:*
-• Нажмите на предмет для детального анализа
-• Используйте ⬅️➡️ для перехода между страницами`
+	text := newMessage(b.lang(chatID)).T("welcome")
+	// В личном чате ID чата совпадает с ID пользователя
+	if b.isAdmin(chatID) {
+		text.T("welcome.admin")
+	}
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
-	b.api.Send(msg)
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = parseModeHTML
+	b.send(msg)
 }
 
-func (b *Bot) sendTopItems(chatID int64) {
+func (b *Bot) sendTopItems(chatID int64, origin *tgbotapi.Message) {
+	lang := b.lang(chatID)
+
 	// Показываем меню категорий
-	text := "📂 *Выберите категорию для анализа:*\n\n"
-	text += "🔪 Ножи - самые дорогие и стабильные инвестиции\n"
-	text += "🔫 Оружие - популярные скины с хорошим потенциалом\n"
-	text += "📦 Кейсы - контейнеры со скинами (только Case)\n"
-	text += "🧤 Перчатки - редкие и ценные предметы\n"
-	text += "🗝️ Ключи - для открытия кейсов\n"
-	text += "📤 Пакеты - капсулы и сувениры\n"
-	text += "🏷️ Стикеры - коллекционная ценность\n"
-	text += "🎯 Брелки - новая категория предметов\n"
-	text += "⭐ Все категории - общий топ"
+	text := newMessage(lang).T("top.menu").String()
+
+	button := func(category string) tgbotapi.InlineKeyboardButton {
+		label := b.getCategoryEmoji(category) + " " + b.getCategoryLabel(lang, category)
+		return callbackButton(label, actionCategory, category)
+	}
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(button("knives"), button("weapons")),
+		tgbotapi.NewInlineKeyboardRow(button("containers"), button("gloves")),
+		tgbotapi.NewInlineKeyboardRow(button("keys"), button("packages")),
+		tgbotapi.NewInlineKeyboardRow(button("stickers"), button("charms")),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔪 Ножи", "cat_knives"),
-			tgbotapi.NewInlineKeyboardButtonData("🔫 Оружие", "cat_weapons"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📦 Кейсы", "cat_containers"),
-			tgbotapi.NewInlineKeyboardButtonData("🧤 Перчатки", "cat_gloves"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🗝️ Ключи", "cat_keys"),
-			tgbotapi.NewInlineKeyboardButtonData("📤 Пакеты", "cat_packages"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏷️ Стикеры", "cat_stickers"),
-			tgbotapi.NewInlineKeyboardButtonData("🎯 Брелки", "cat_charms"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⭐ Все категории", "cat_all"),
+			callbackButton(i18n.T(lang, "top.all_button"), actionCategory, "all"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.show(chatID, origin, screen{Text: text, ParseMode: parseModeHTML, Keyboard: keyboard})
 }
 
-func (b *Bot) sendTopItemsPage(chatID int64, page int) {
-	b.sendTopItemsByCategory(chatID, "all", page)
+func (b *Bot) sendTopItemsPage(chatID int64, origin *tgbotapi.Message, page int) {
+	b.sendTopItemsByCategory(chatID, origin, "all", page)
 }
 
-func (b *Bot) sendTopItemsByCategory(chatID int64, category string, page int) {
+func (b *Bot) sendTopItemsByCategory(chatID int64, origin *tgbotapi.Message, category string, page int) {
+	lang := b.lang(chatID)
 	itemsPerPage := 5
-	offset := (page - 1) * itemsPerPage
-	
+
 	// Получаем предметы по категории
 	var allTrends []analyzer.ItemTrend
 	var err error
-	
+
 	if category == "all" {
 		allTrends, err = b.analyzer.GetTopItems(50)
 	} else {
 		allTrends, err = b.analyzer.GetTopItemsByCategory(category, 50)
 	}
-	
+
 	if err != nil {
-		msg := tgbotapi.NewMessage(chatID, "Ошибка получения данных. Попробуйте позже.")
-		if _, e := b.api.Send(msg); e != nil {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "common.data_error"))
+		if _, e := b.send(msg); e != nil {
 			log.Printf("send error: %v", e)
 		}
 		return
 	}
 
 	if len(allTrends) == 0 {
-		msg := tgbotapi.NewMessage(chatID, "В данной категории пока нет анализируемых предметов. Попробуйте /analyze для обновления.")
-		if _, e := b.api.Send(msg); e != nil {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "top.empty"))
+		if _, e := b.send(msg); e != nil {
 			log.Printf("send error: %v", e)
 		}
 		return
@@ -206,225 +333,126 @@ func (b *Bot) sendTopItemsByCategory(chatID int64, category string, page int) {
 	}
 
 	// Получаем предметы для текущей страницы
-	start := offset
-	end := offset + itemsPerPage
+	start := (page - 1) * itemsPerPage
+	end := start + itemsPerPage
 	if end > len(allTrends) {
 		end = len(allTrends)
 	}
-	
+
 	trends := allTrends[start:end]
 
-	categoryName := b.getCategoryName(category)
-	text := fmt.Sprintf("🏆 %s (стр. %d/%d)\n\n", categoryName, page, totalPages)
-	
+	categoryName := b.getCategoryName(lang, category)
+	text := newMessage(lang).T("top.page_title", categoryName, page, totalPages)
+
 	for i, trend := range trends {
 		emoji := b.getRecommendationEmoji(trend.Recommendation)
 		catEmoji := b.getCategoryEmoji(trend.Category)
 		globalIndex := start + i + 1
-		
-		text += fmt.Sprintf("%d. %s %s %s\n", globalIndex, emoji, catEmoji, trend.MarketName)
-		text += fmt.Sprintf("   📊 Рейтинг: %d/10 | 💰 %.2f ₽ | 📈 %.1f%%\n", 
-			trend.TrendScore, trend.CurrentPrice, trend.GrowthRate)
-		text += fmt.Sprintf("   💡 %s\n\n", b.getInvestmentAdvice(trend))
+
+		text.Textf("%d. %s %s %s\n", globalIndex, emoji, catEmoji, trend.MarketName)
+		text.T("top.item_line", trend.TrendScore, trend.CurrentPrice, trend.GrowthRate)
+		text.Textf("   💡 %s\n\n", b.getInvestmentAdvice(lang, trend))
 	}
 
 	// Создаем клавиатуру с предметами
 	var keyboard [][]tgbotapi.InlineKeyboardButton
-	
+
 	for _, trend := range trends {
 		buttonText := fmt.Sprintf("📊 %s", b.truncateString(trend.MarketName, 30))
-		callbackData := fmt.Sprintf("item_%d", trend.ItemID)
-		button := tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData)
+		button := callbackButton(buttonText, actionItem, trend.ItemID, actionCategory, category, page)
 		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
 	}
 
 	// Добавляем кнопки навигации
 	var navButtons []tgbotapi.InlineKeyboardButton
-	
+
 	if page > 1 {
-		prevCallback := fmt.Sprintf("page_%d_%s", page-1, category)
-		prevButton := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", prevCallback)
+		prevButton := callbackButton(i18n.T(lang, "nav.prev"), actionCategory, category, page-1)
 		navButtons = append(navButtons, prevButton)
 	}
-	
+
 	if page < totalPages {
-		nextCallback := fmt.Sprintf("page_%d_%s", page+1, category)
-		nextButton := tgbotapi.NewInlineKeyboardButtonData("Вперед ➡️", nextCallback)
+		nextButton := callbackButton(i18n.T(lang, "nav.next"), actionCategory, category, page+1)
 		navButtons = append(navButtons, nextButton)
 	}
-	
+
 	if len(navButtons) > 0 {
 		keyboard = append(keyboard, navButtons)
 	}
 
 	// Кнопка возврата к категориям
-	backButton := tgbotapi.NewInlineKeyboardButtonData("📂 Выбрать категорию", "back_to_top")
+	backButton := callbackButton(i18n.T(lang, "nav.choose_category"), actionTopMenu)
 	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{backButton})
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	// Без ParseMode, чтобы избежать ошибок Markdown на названиях предметов
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
-	if _, e := b.api.Send(msg); e != nil {
-		log.Printf("send error: %v", e)
-	}
+	b.show(chatID, origin, screen{
+		Text:      text.String(),
+		ParseMode: parseModeHTML,
+		Keyboard:  tgbotapi.NewInlineKeyboardMarkup(keyboard...),
+	})
 }
 
-func (b *Bot) runAnalysis(chatID int64) {
-	msg := tgbotapi.NewMessage(chatID, "🔄 Запускаю анализ рынка... Это может занять несколько минут.")
-	b.api.Send(msg)
+func (b *Bot) sendItemDetails(chatID int64, origin *tgbotapi.Message, itemID int, back callbackPayload) {
+	lang := b.lang(chatID)
 
-	go func() {
-		err := b.analyzer.AnalyzeAllItems()
-		if err != nil {
-			errorMsg := tgbotapi.NewMessage(chatID, "❌ Ошибка при анализе: "+err.Error())
-			b.api.Send(errorMsg)
-			return
+	// Получаем детальную информацию о предмете
+	item, err := b.db.GetItemDetails(itemID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "item.error"))
+		if _, e := b.send(msg); e != nil {
+			log.Printf("send error: %v", e)
 		}
-
-		successMsg := tgbotapi.NewMessage(chatID, "✅ Анализ завершен! Используйте /top для просмотра результатов.")
-		b.api.Send(successMsg)
-	}()
-}
-
-func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
-	// Отвечаем на callback чтобы убрать "часики"
-	b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
-
-	if callback.Data == "back_to_top" {
-		b.sendTopItems(callback.Message.Chat.ID)
 		return
 	}
 
-	if len(callback.Data) > 4 && callback.Data[:4] == "cat_" {
-		category := callback.Data[4:]
-		b.sendTopItemsByCategory(callback.Message.Chat.ID, category, 1)
-		return
-	}
+	catEmoji := b.getCategoryEmoji(item.Category)
 
-	if len(callback.Data) > 5 && callback.Data[:5] == "item_" {
-		itemIDStr := callback.Data[5:]
-		itemID, err := strconv.Atoi(itemIDStr)
-		if err != nil {
-			return
-		}
+	text := newMessage(lang).T("item.title")
+	text.Textf("%s <b>%s</b>\n", catEmoji, item.MarketName)
+	text.T("item.category", b.getCategoryName(lang, item.Category))
 
-		b.sendItemDetails(callback.Message.Chat.ID, itemID)
-		return
-	}
+	text.T("item.price", item.CurrentPrice)
 
-	if len(callback.Data) > 5 && callback.Data[:5] == "page_" {
-		parts := strings.Split(callback.Data[5:], "_")
-		if len(parts) >= 1 {
-			page, err := strconv.Atoi(parts[0])
-			if err != nil {
-				return
-			}
-			
-			if len(parts) >= 2 {
-				category := parts[1]
-				b.sendTopItemsByCategory(callback.Message.Chat.ID, category, page)
-			} else {
-				b.sendTopItemsPage(callback.Message.Chat.ID, page)
-			}
-		}
-		return
-	}
+	if a := item.Analysis; a != nil {
+		emoji := b.getRecommendationEmoji(a.Recommendation)
 
-	// Обработка кнопок бюджета
-	if len(callback.Data) > 7 && callback.Data[:7] == "budget_" {
-		action := callback.Data[7:]
-		
-		switch action {
-		case "5000":
-			b.sendBudgetResults(callback.Message.Chat.ID, 5000)
-		case "10000":
-			b.sendBudgetResults(callback.Message.Chat.ID, 10000)
-		case "25000":
-			b.sendBudgetResults(callback.Message.Chat.ID, 25000)
-		case "50000":
-			b.sendBudgetResults(callback.Message.Chat.ID, 50000)
-		case "100000":
-			b.sendBudgetResults(callback.Message.Chat.ID, 100000)
-		case "custom":
-			msg := tgbotapi.NewMessage(callback.Message.Chat.ID, 
-				"💰 Введите ваш бюджет числом в рублях:\nНапример: 15000")
-			b.api.Send(msg)
-		case "new":
-			b.sendBudgetCalculator(callback.Message.Chat.ID)
-		}
-		return
-	}
-}
+		text.T("item.growth", a.GrowthRate)
+		text.T("item.volatility", a.Volatility)
+		text.T("item.score", a.TrendScore)
+		text.T("item.recommendation", emoji, a.Recommendation)
 
-func (b *Bot) sendItemDetails(chatID int64, itemID int) {
-	// Получаем детальную информацию о предмете
-	query := `SELECT i.hash_name, i.market_name, i.category, i.image_url, 
-			  ia.growth_rate, ia.volatility, ia.trend_score, ia.recommendation,
-			  (SELECT price FROM price_history WHERE item_id = $1 ORDER BY recorded_at DESC LIMIT 1) as current_price,
-			  (SELECT COUNT(*) FROM price_history WHERE item_id = $1) as data_points
-			  FROM items i
-			  JOIN item_analysis ia ON i.id = ia.item_id
-			  WHERE i.id = $1`
-
-	var hashName, marketName, category, imageURL, recommendation string
-	var growthRate, volatility, currentPrice float64
-	var trendScore, dataPoints int
-
-	err := b.db.QueryRow(query, itemID).Scan(&hashName, &marketName, &category, &imageURL,
-		&growthRate, &volatility, &trendScore, &recommendation, &currentPrice, &dataPoints)
-	if err != nil {
-		msg := tgbotapi.NewMessage(chatID, "Ошибка получения информации о предмете.")
-		if _, e := b.api.Send(msg); e != nil {
-			log.Printf("send error: %v", e)
+		// Метрики риска по дневным доходностям
+		for _, r := range item.Risk {
+			text.T("item.risk", r.WindowDays, r.Volatility, r.Confidence*100, r.VaR, r.CVaR, r.MaxDrawdown, r.Sharpe, r.Sortino)
 		}
-		return
+
+		// Детальная интерпретация (фрагменты каталога с разметкой)
+		text.T("item.why")
+		text.Raw(b.getDetailedAnalysis(lang, a.TrendScore, a.GrowthRate, item.CurrentPrice, item.Category, a.Volatility))
+
+		text.T("item.strategy")
+		text.Raw(b.getInvestmentStrategy(lang, a.TrendScore, a.Recommendation, item.CurrentPrice, item.Category))
+	} else {
+		text.T("item.not_analyzed")
 	}
 
-	emoji := b.getRecommendationEmoji(recommendation)
-	catEmoji := b.getCategoryEmoji(category)
-	
-	text := fmt.Sprintf("📊 Подробный инвестиционный анализ\n\n")
-	text += fmt.Sprintf("%s %s\n", catEmoji, marketName)
-	text += fmt.Sprintf("📂 Категория: %s\n\n", b.getCategoryName(category))
-	
-	text += fmt.Sprintf("💰 Цена: %.2f ₽\n", currentPrice)
-	text += fmt.Sprintf("📈 Рост: %.1f%% за период\n", growthRate)
-	text += fmt.Sprintf("📊 Волатильность: %.1f%%\n", volatility)
-	text += fmt.Sprintf("⭐ Рейтинг: %d/10\n", trendScore)
-	text += fmt.Sprintf("%s Рекомендация: %s\n\n", emoji, recommendation)
-
-	// Детальная интерпретация
-	text += "🔍 Почему стоит рассмотреть:\n"
-	text += b.getDetailedAnalysis(trendScore, growthRate, currentPrice, category, volatility)
-	
-	text += "\n📈 Инвестиционная стратегия:\n"
-	text += b.getInvestmentStrategy(trendScore, recommendation, currentPrice, category)
-	
-	text += fmt.Sprintf("\n📊 Надежность данных: %d точек\n", dataPoints)
+	text.T("item.data_points", i18n.N(lang, item.DataPoints, "unit.point"))
 
 	// Кнопка для возврата к списку
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к списку", "back_to_top"),
+			back.button(i18n.T(lang, "nav.back_to_list")),
 		),
 	)
 
-	// Если есть валидный URL изображения, отправляем фото
-	if imageURL != "" && imageURL != "https://steamcommunity-a.akamaihd.net/economy/image/placeholder" {
-		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(imageURL))
-		photo.Caption = text
-		photo.ReplyMarkup = keyboard
-		if _, e := b.api.Send(photo); e != nil {
-			log.Printf("send error: %v", e)
-		}
-	} else {
-		// Отправляем текстовое сообщение если нет изображения
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ReplyMarkup = keyboard
-		if _, e := b.api.Send(msg); e != nil {
-			log.Printf("send error: %v", e)
-		}
+	details := screen{Text: text.String(), ParseMode: parseModeHTML, Keyboard: keyboard}
+
+	// Если есть валидный URL изображения и текст помещается в подпись, показываем фото
+	if item.ImageURL != "" && item.ImageURL != "https://steamcommunity-a.akamaihd.net/economy/image/placeholder" &&
+		text.Len() <= maxCaptionLength {
+		details.PhotoURL = item.ImageURL
 	}
+
+	b.show(chatID, origin, details)
 }
 
 func (b *Bot) getRecommendationEmoji(recommendation string) string {
@@ -440,33 +468,34 @@ func (b *Bot) getRecommendationEmoji(recommendation string) string {
 	}
 }
 
+// Обрезка до maxLen символов; режется по символам, а не по байтам,
+// иначе в названиях со "★", "™" и кириллицей остается неверный UTF-8,
+// и Telegram отклоняет сообщение целиком
 func (b *Bot) truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
 
-func (b *Bot) getCategoryName(category string) string {
+// Заголовок топа категории ("🔪 ТОП Ножей")
+func (b *Bot) getCategoryName(lang i18n.Lang, category string) string {
 	switch category {
-	case "knives":
-		return "🔪 ТОП Ножей"
-	case "weapons":
-		return "🔫 ТОП Оружия"
-	case "containers":
-		return "📦 ТОП Кейсов"
-	case "keys":
-		return "🗝️ ТОП Ключей"
-	case "packages":
-		return "📤 ТОП Пакетов"
-	case "gloves":
-		return "🧤 ТОП Перчаток"
-	case "stickers":
-		return "🏷️ ТОП Стикеров"
-	case "charms":
-		return "🎯 ТОП Брелков"
+	case "knives", "weapons", "containers", "keys", "packages", "gloves", "stickers", "charms":
+		return b.getCategoryEmoji(category) + " " + i18n.T(lang, "category."+category+".top")
+	default:
+		return "⭐ " + i18n.T(lang, "category.all.top")
+	}
+}
+
+// Название категории без префикса "ТОП"
+func (b *Bot) getCategoryLabel(lang i18n.Lang, category string) string {
+	switch category {
+	case "knives", "weapons", "containers", "keys", "packages", "gloves", "stickers", "charms":
+		return i18n.T(lang, "category."+category)
 	default:
-		return "⭐ ТОП Всех категорий"
+		return i18n.T(lang, "category.other")
 	}
 }
 
@@ -493,312 +522,358 @@ func (b *Bot) getCategoryEmoji(category string) string {
 	}
 }
 
-func (b *Bot) getInvestmentAdvice(trend analyzer.ItemTrend) string {
+func (b *Bot) getInvestmentAdvice(lang i18n.Lang, trend analyzer.ItemTrend) string {
 	if trend.TrendScore >= 8 && trend.GrowthRate > 10 {
-		return "Сильная покупка - отличный потенциал роста!"
+		return i18n.T(lang, "advice.strong_buy")
 	} else if trend.TrendScore >= 7 && trend.CurrentPrice < 10 {
-		return "Дешевый актив с хорошими перспективами"
+		return i18n.T(lang, "advice.cheap")
 	} else if trend.TrendScore >= 7 && trend.CurrentPrice > 100 {
-		return "Стабильная дорогая инвестиция"
+		return i18n.T(lang, "advice.premium")
 	} else if trend.TrendScore >= 6 {
-		return "Умеренный потенциал, подходит для диверсификации"
+		return i18n.T(lang, "advice.moderate")
 	} else {
-		return "Рискованная инвестиция, требует анализа"
+		return i18n.T(lang, "advice.risky")
 	}
 }
 
-func (b *Bot) getDetailedAnalysis(trendScore int, growthRate, currentPrice float64, category string, volatility float64) string {
+func (b *Bot) getDetailedAnalysis(lang i18n.Lang, trendScore int, growthRate, currentPrice float64, category string, volatility float64) string {
 	analysis := ""
-	
+
 	// Анализ по рейтингу
 	if trendScore >= 8 {
-		analysis += "✅ Высокий рейтинг указывает на сильные перспективы роста\n"
+		analysis += i18n.T(lang, "analysis.score_high")
 	} else if trendScore >= 6 {
-		analysis += "⚡ Средний рейтинг - предмет имеет потенциал\n"
+		analysis += i18n.T(lang, "analysis.score_medium")
 	}
-	
+
 	// Анализ по росту
 	if growthRate > 15 {
-		analysis += "🚀 Демонстрирует активный рост цены\n"
+		analysis += i18n.T(lang, "analysis.growth_fast")
 	} else if growthRate > 5 {
-		analysis += "📈 Стабильный положительный тренд\n"
+		analysis += i18n.T(lang, "analysis.growth_steady")
 	}
-	
+
 	// Анализ по цене
 	if currentPrice < 10 {
-		analysis += "💎 Низкая цена входа - минимальный риск\n"
+		analysis += i18n.T(lang, "analysis.price_low")
 	} else if currentPrice > 100 {
-		analysis += "💰 Premium сегмент - для серьезных инвесторов\n"
+		analysis += i18n.T(lang, "analysis.price_premium")
 	}
-	
+
 	// Анализ по категории
 	switch category {
-	case "knives":
-		analysis += "🔪 Ножи - самая стабильная категория для долгосрочных инвестиций\n"
-	case "weapons":
-		analysis += "🔫 Оружие - высокая ликвидность и спрос\n"
-	case "containers":
-		analysis += "📦 Контейнеры - растут в цене со временем\n"
-	case "gloves":
-		analysis += "🧤 Перчатки - редкая категория с ограниченным предложением\n"
+	case "knives", "weapons", "containers", "gloves":
+		analysis += i18n.T(lang, "analysis.category."+category)
 	}
-	
+
 	// Анализ волатильности
 	if volatility < 10 {
-		analysis += "🛡️ Стабильная цена - низкий риск потерь\n"
+		analysis += i18n.T(lang, "analysis.volatility_low")
 	} else if volatility > 30 {
-		analysis += "⚡ Высокая волатильность - возможны быстрые изменения\n"
+		analysis += i18n.T(lang, "analysis.volatility_high")
 	}
-	
+
 	return analysis
 }
 
-func (b *Bot) getInvestmentStrategy(trendScore int, recommendation string, currentPrice float64, category string) string {
+func (b *Bot) getInvestmentStrategy(lang i18n.Lang, trendScore int, recommendation string, currentPrice float64, category string) string {
 	strategy := ""
-	
+
 	switch recommendation {
 	case "BUY":
-		strategy += "🟢 *Покупать сейчас* - оптимальная точка входа\n"
+		strategy += i18n.T(lang, "strategy.buy")
 		if currentPrice < 50 {
-			strategy += "💡 Можно купить несколько штук для диверсификации\n"
+			strategy += i18n.T(lang, "strategy.buy_several")
 		}
-		strategy += "⏰ Рекомендуемый срок холда: 3-6 месяцев\n"
-		
+		strategy += i18n.T(lang, "strategy.buy_hold")
+
 	case "HOLD":
-		strategy += "🟡 *Держать* - если уже есть в портфеле\n"
-		strategy += "📊 Следить за динамикой, возможна покупка при снижении\n"
-		
+		strategy += i18n.T(lang, "strategy.hold")
+
 	case "SELL":
-		strategy += "🔴 *Продавать* - высокий риск снижения\n"
-		strategy += "💸 Рассмотреть фиксацию прибыли если есть\n"
+		strategy += i18n.T(lang, "strategy.sell")
 	}
-	
+
 	// Специфичные стратегии по категориям
 	switch category {
-	case "knives":
-		strategy += "🔪 Ножи лучше покупать в хорошем состоянии (MW, FN)\n"
-	case "containers":
-		strategy += "📦 Контейнеры - долгосрочная игра, держать минимум год\n"
-	case "weapons":
-		strategy += "🔫 Популярное оружие (AK, M4, AWP) предпочтительнее\n"
+	case "knives", "containers", "weapons":
+		strategy += i18n.T(lang, "strategy.category."+category)
 	}
-	
+
 	return strategy
 }
 
 // Калькулятор бюджета
 func (b *Bot) sendBudgetCalculator(chatID int64) {
-	text := `💰 *Калькулятор бюджета*
-
-Рассчитаем оптимальный портфель для вашего капитала с минимальной доходностью **210%**!
-
-🎯 *Что делает калькулятор:*
-• Анализирует топ предметы с лучшими прогнозами
-• Распределяет ваш бюджет между перспективными скинами
-• Учитывает риски и диверсификацию
-• Показывает ожидаемую прибыль через 6-12 месяцев
-
-💡 *Введите ваш бюджет в рублях:*
-Например: 10000`
+	lang := b.lang(chatID)
 
 	// Создаем клавиатуру с примерами бюджетов
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("5 000₽", "budget_5000"),
-			tgbotapi.NewInlineKeyboardButtonData("10 000₽", "budget_10000"),
+			callbackButton("5 000₽", actionBudget, 5000),
+			callbackButton("10 000₽", actionBudget, 10000),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("25 000₽", "budget_25000"),
-			tgbotapi.NewInlineKeyboardButtonData("50 000₽", "budget_50000"),
+			callbackButton("25 000₽", actionBudget, 25000),
+			callbackButton("50 000₽", actionBudget, 50000),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("100 000₽", "budget_100000"),
-			tgbotapi.NewInlineKeyboardButtonData("💬 Ввести свой", "budget_custom"),
+			callbackButton("100 000₽", actionBudget, 100000),
+			callbackButton(i18n.T(lang, "budget.custom_button"), actionBudgetCustom),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
+	// Калькулятор предлагает ввести сумму текстом
+	b.setState(chatID, stateAwaitingBudget, "")
+
+	params := b.params.Get().Budget
+	intro := newMessage(lang).T("budget.intro", (params.MinROI-1)*100, params.Venue)
+	msg := tgbotapi.NewMessage(chatID, intro.String())
+	msg.ParseMode = parseModeHTML
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // Структура для рекомендации покупки
 type BudgetRecommendation struct {
-	ItemName        string
-	Category        string
-	Price           float64
-	Quantity        int
-	TotalCost       float64
-	ExpectedROI     float64
+	ItemName       string
+	Category       string
+	Price          float64
+	Quantity       int
+	TotalCost      float64
+	ExpectedROI    float64 // после издержек (множитель)
+	GrossROI       float64 // по одному росту цены (множитель)
+	ExpectedProfit float64
+	TrendScore     int
+	Recommendation string
+}
+
+// Рекомендованный портфель
+type BudgetPlan struct {
+	Recommendations []BudgetRecommendation
 	ExpectedProfit  float64
-	TrendScore      int
-	Recommendation  string
+	ProfitStdDev    float64 // стандартное отклонение прибыли за горизонт
+	Risk            string  // уровень риска: config.RiskLow/RiskMedium/RiskHigh
+	HorizonDays     int
+	MinROI          float64               // порог чистого ROI кандидатов (множитель)
+	Venue           string                // площадка продажи, по которой считаются издержки
+	Fees            config.VenueFees      // ее комиссии
+	Simulation      *optimizer.Simulation // nil, если портфель не удалось смоделировать
 }
 
-// Расчет оптимального портфеля
-func (b *Bot) calculateOptimalPortfolio(budget float64) ([]BudgetRecommendation, float64, error) {
-	// Получаем топ предметы с минимальным ROI 210%
-	items, err := b.analyzer.GetBestInvestmentItems(50, 2.1) // минимум 210% ROI
+// Расчет оптимального портфеля: оценка доходностей и ковариации по дневным
+// ценам и оптимизация "доходность минус штраф за риск" для уровня risk
+func (b *Bot) calculateOptimalPortfolio(budget float64, risk string) (*BudgetPlan, error) {
+	// Параметры читаются один раз, чтобы перезагрузка не смешала два набора
+	current := b.params.Get()
+	params := current.Budget
+
+	items, err := b.analyzer.GetBestInvestmentItems(50)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-
-	var recommendations []BudgetRecommendation
-	remainingBudget := budget
-	totalExpectedProfit := 0.0
-
-	// Распределяем бюджет по категориям (диверсификация)
-	categoryAllocations := map[string]float64{
-		"knives":     0.4,  // 40% на ножи (стабильно)
-		"weapons":    0.3,  // 30% на оружие (ликвидно)
-		"containers": 0.15, // 15% на кейсы (долгосрочно)
-		"gloves":     0.1,  // 10% на перчатки (премиум)
-		"stickers":   0.05, // 5% на стикеры (высокий риск)
+	plan := &BudgetPlan{
+		Risk:        risk,
+		HorizonDays: params.HorizonDays,
+		MinROI:      params.MinROI,
+		Venue:       params.Venue,
+		Fees:        params.Fees[params.Venue],
+	}
+	if len(items) == 0 {
+		return plan, nil
 	}
 
-	for category, allocation := range categoryAllocations {
-		categoryBudget := budget * allocation
-		categoryItems := filterItemsByCategory(items, category)
-		
-		for _, item := range categoryItems {
-			if remainingBudget < item.Price || categoryBudget < item.Price {
-				continue
-			}
+	// Порядок кандидатов определяет разрешение равенств в оптимизаторе
+	sort.Slice(items, func(i, j int) bool { return items[i].ItemID < items[j].ItemID })
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ItemID
+	}
 
-			// Рассчитываем количество предметов для покупки
-			maxQuantity := int(categoryBudget / item.Price)
-			if maxQuantity > 3 {
-				maxQuantity = 3 // Ограничиваем максимум 3 штуки одного предмета
-			}
-			if maxQuantity == 0 {
-				continue
-			}
+	since := time.Now().AddDate(0, 0, -current.Analysis.HistoryDays)
+	prices, err := b.db.GetDailyPrices(ids, since)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := b.db.GetLatestVolumes(ids)
+	if err != nil {
+		return nil, err
+	}
 
-			totalCost := item.Price * float64(maxQuantity)
-			expectedProfit := totalCost * (item.ExpectedROI - 1.0)
-
-			recommendation := BudgetRecommendation{
-				ItemName:       item.MarketName,
-				Category:       item.Category,
-				Price:          item.Price,
-				Quantity:       maxQuantity,
-				TotalCost:      totalCost,
-				ExpectedROI:    item.ExpectedROI,
-				ExpectedProfit: expectedProfit,
-				TrendScore:     item.TrendScore,
-				Recommendation: item.Recommendation,
-			}
+	history := optimizer.NewHistory(ids, prices)
+	returns, covariance := history.Estimate(params.HorizonDays)
 
-			recommendations = append(recommendations, recommendation)
-			remainingBudget -= totalCost
-			categoryBudget -= totalCost
-			totalExpectedProfit += expectedProfit
+	problem := optimizer.Problem{
+		Returns:      returns,
+		Covariance:   covariance,
+		Budget:       budget,
+		RiskAversion: params.RiskAversion.For(risk),
+		MaxPositions: params.MaxItems,
+		CategoryCaps: params.Allocations,
+		Costs:        optimizer.NewCosts(params),
+		MinReturn:    params.MinROI - 1,
+	}
+	byID := make(map[int]analyzer.ItemTrend, len(items))
+	for i, item := range items {
+		byID[item.ItemID] = item
 
-			// Ограничиваем количество рекомендаций
-			if len(recommendations) >= 8 {
-				break
-			}
+		// Без достаточной истории риск не оценить — такой предмет не покупаем
+		maxUnits := 0
+		if history.Observations(i) >= optimizer.MinObservations {
+			maxUnits = liquidityLimit(volumes, item.ItemID, params.LiquidityShare, params.MaxPerItem)
 		}
-	}
+		problem.Assets = append(problem.Assets, optimizer.Asset{
+			ID:       item.ItemID,
+			Category: item.Category,
+			Price:    item.Price,
+			MaxUnits: maxUnits,
+			Volume:   volumes[item.ItemID],
+		})
+	}
+
+	result := optimizer.Solve(problem)
+	for _, pos := range result.Positions {
+		item := byID[pos.Asset.ID]
+		plan.Recommendations = append(plan.Recommendations, BudgetRecommendation{
+			ItemName:       item.MarketName,
+			Category:       item.Category,
+			Price:          pos.Asset.Price,
+			Quantity:       pos.Units,
+			TotalCost:      pos.Cost,
+			ExpectedROI:    1 + pos.Return,
+			GrossROI:       1 + pos.GrossReturn,
+			ExpectedProfit: pos.Cost * pos.Return,
+			TrendScore:     item.TrendScore,
+			Recommendation: item.Recommendation,
+		})
+	}
+	plan.ExpectedProfit = result.ExpectedProfit
+	plan.ProfitStdDev = result.StdDev
+	plan.Simulation = optimizer.Simulate(history, result.Positions, problem.Costs, params.HorizonDays, params.Simulations)
+	return plan, nil
+}
 
-	return recommendations, totalExpectedProfit, nil
+// Сколько штук предмета можно купить, не двигая рынок: доля последнего
+// известного объема, но не больше maxPerItem. Без данных об объеме — одна штука.
+func liquidityLimit(volumes map[int]int, itemID int, share float64, maxPerItem int) int {
+	volume, ok := volumes[itemID]
+	if !ok {
+		return 1
+	}
+	limit := int(float64(volume) * share)
+	if limit < 1 && volume > 0 {
+		limit = 1
+	}
+	if limit > maxPerItem {
+		limit = maxPerItem
+	}
+	return limit
 }
 
-// Отправляем результаты расчета бюджета
-func (b *Bot) sendBudgetResults(chatID int64, budget float64) {
-	recommendations, totalExpectedProfit, err := b.calculateOptimalPortfolio(budget)
+// Отправляем результаты расчета бюджета для уровня риска risk
+func (b *Bot) sendBudgetResults(chatID int64, budget float64, risk string) {
+	lang := b.lang(chatID)
+
+	plan, err := b.calculateOptimalPortfolio(budget, risk)
 	if err != nil {
-		msg := tgbotapi.NewMessage(chatID, "❌ Ошибка при расчете портфеля. Попробуйте позже.")
-		b.api.Send(msg)
+		i18n.Logf("log.budget.failed", chatID, err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.error"))
+		b.send(msg)
 		return
 	}
 
-	if len(recommendations) == 0 {
-		msg := tgbotapi.NewMessage(chatID, "❌ Не найдено подходящих предметов для вашего бюджета с требуемой доходностью 210%+")
-		b.api.Send(msg)
+	if len(plan.Recommendations) == 0 {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.nothing_found", (plan.MinROI-1)*100, plan.Venue))
+		b.send(msg)
 		return
 	}
 
-	text := fmt.Sprintf("💰 *Оптимальный портфель для %s₽*\n\n", formatPrice(budget))
+	text := newMessage(lang).T("budget.title", formatPrice(budget))
 
 	totalInvested := 0.0
-	for _, rec := range recommendations {
+	for _, rec := range plan.Recommendations {
 		totalInvested += rec.TotalCost
 	}
 
-	expectedROI := ((totalInvested + totalExpectedProfit) / totalInvested) * 100
-	
-	text += fmt.Sprintf("📊 *Общая статистика:*\n")
-	text += fmt.Sprintf("💵 К инвестированию: %s₽\n", formatPrice(totalInvested))
-	text += fmt.Sprintf("💰 Остаток: %s₽\n", formatPrice(budget-totalInvested))
-	text += fmt.Sprintf("📈 Ожидаемая прибыль: %s₽\n", formatPrice(totalExpectedProfit))
-	text += fmt.Sprintf("🎯 Общий ROI: %.0f%%\n\n", expectedROI)
+	expectedROI := plan.ExpectedProfit / totalInvested * 100
 
-	text += "🛒 *Рекомендуемые покупки:*\n\n"
+	text.T("budget.stats",
+		formatPrice(totalInvested), formatPrice(budget-totalInvested),
+		formatPrice(plan.ExpectedProfit), expectedROI)
+	text.T("budget.costs", plan.Venue, plan.Fees.Sale*100, plan.Fees.Withdraw*100)
+	text.T("budget.risk", i18n.T(lang, "budget.risk_"+plan.Risk), plan.HorizonDays, formatPrice(plan.ProfitStdDev))
+	if sim := plan.Simulation; sim != nil {
+		text.T("budget.simulation", plan.HorizonDays,
+			formatPnL(sim.Quantile(0.5)-sim.Cost, sim.Cost),
+			formatPnL(sim.Quantile(0.05)-sim.Cost, sim.Cost),
+			formatPnL(sim.Quantile(0.95)-sim.Cost, sim.Cost),
+			sim.LossProbability*100)
+	}
+
+	text.T("budget.purchases")
 
-	for i, rec := range recommendations {
+	for i, rec := range plan.Recommendations {
 		emoji := b.getCategoryEmoji(rec.Category)
-		text += fmt.Sprintf("%d. %s *%s*\n", i+1, emoji, rec.ItemName)
-		text += fmt.Sprintf("   💸 %s₽ × %d шт = %s₽\n", 
+		// Каждая позиция — один блок, чтобы не разрывать ее между сообщениями
+		entry := fmt.Sprintf("%d. %s <b>%s</b>\n", i+1, emoji, escapeHTML(rec.ItemName))
+		entry += i18n.T(lang, "budget.item_cost",
 			formatPrice(rec.Price), rec.Quantity, formatPrice(rec.TotalCost))
-		text += fmt.Sprintf("   📈 ROI: %.0f%% (+%s₽)\n", 
-			rec.ExpectedROI*100, formatPrice(rec.ExpectedProfit))
-		text += fmt.Sprintf("   ⭐ Рейтинг: %.1f/10\n\n", rec.TrendScore)
-	}
-
-	text += "⚠️ *Важно:*\n"
-	text += "• Это прогноз, реальная доходность может отличаться\n"
-	text += "• Инвестируйте только те средства, которые готовы потерять\n"
-	text += "• Рекомендуемый срок холда: 6-12 месяцев\n"
-	text += "• Следите за обновлениями игры и рынка"
-
-	// Разбиваем длинное сообщение если нужно
-	if len(text) > 4000 {
-		// Отправляем первую часть
-		firstPart := text[:4000]
-		lastNewline := strings.LastIndex(firstPart, "\n")
-		if lastNewline > 0 {
-			firstPart = firstPart[:lastNewline]
+		entry += i18n.T(lang, "budget.item_roi",
+			(rec.ExpectedROI-1)*100, formatPrice(rec.ExpectedProfit), (rec.GrossROI-1)*100)
+		entry += i18n.T(lang, "budget.item_score", rec.TrendScore)
+		text.Raw(entry)
+	}
+
+	text.T("budget.disclaimer")
+
+	// Разбиваем длинное сообщение по границам блоков
+	for _, part := range text.Split(maxMessageLength) {
+		msg := tgbotapi.NewMessage(chatID, part)
+		msg.ParseMode = parseModeHTML
+		if _, e := b.send(msg); e != nil {
+			log.Printf("send error: %v", e)
 		}
-		
-		msg := tgbotapi.NewMessage(chatID, firstPart)
-		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
-		
-		// Отправляем вторую часть
-		secondPart := text[len(firstPart):]
-		msg = tgbotapi.NewMessage(chatID, secondPart)
-		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
-	} else {
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
 	}
 
-	// Добавляем кнопку для нового расчета
+	if plan.Simulation != nil {
+		b.sendSimulationChart(chatID, plan)
+	}
+
+	// Пересчет с другим уровнем риска и новый расчет
+	var riskRow []tgbotapi.InlineKeyboardButton
+	for _, level := range []string{config.RiskLow, config.RiskMedium, config.RiskHigh} {
+		if level != plan.Risk {
+			riskRow = append(riskRow, callbackButton(i18n.T(lang, "budget.risk_button_"+level), actionBudget, int(budget), level))
+		}
+	}
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		riskRow,
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔄 Новый расчет", "budget_new"),
-			tgbotapi.NewInlineKeyboardButtonData("📊 Топ предметы", "back_to_top"),
+			callbackButton(i18n.T(lang, "budget.new_button"), actionBudgetNew),
+			callbackButton(i18n.T(lang, "budget.top_button"), actionTopMenu),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, "💡 Хотите пересчитать с другим бюджетом?")
+	msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.again"))
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
-// Фильтрация предметов по категории
-func filterItemsByCategory(items []analyzer.ItemTrend, category string) []analyzer.ItemTrend {
-	var filtered []analyzer.ItemTrend
-	for _, item := range items {
-		if item.Category == category {
-			filtered = append(filtered, item)
-		}
+// Веерная диаграмма смоделированной стоимости портфеля
+func (b *Bot) sendSimulationChart(chatID int64, plan *BudgetPlan) {
+	lang := b.lang(chatID)
+
+	png, err := b.charts.GenerateSimulationChart(lang, plan.Simulation)
+	if err != nil {
+		i18n.Logf("log.budget.chart_failed", chatID, err)
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "simulation.png", Bytes: png})
+	photo.Caption = i18n.T(lang, "budget.simulation_caption", len(plan.Simulation.Final), plan.HorizonDays)
+	if _, e := b.send(photo); e != nil {
+		log.Printf("send error: %v", e)
 	}
-	return filtered
 }
 
 // Форматирование цены
@@ -809,4 +884,4 @@ func formatPrice(price float64) string {
 		return fmt.Sprintf("%.0fK", price/1000)
 	}
 	return fmt.Sprintf("%.0f", price)
-}
\ No newline at end of file
+}
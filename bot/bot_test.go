@@ -0,0 +1,32 @@
+package bot
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"короткая строка", "AK-47 | Redline", 30, "AK-47 | Redline"},
+		{"ровно по длине", "★ Karambit", 10, "★ Karambit"},
+		{"многобайтовые символы", "★ StatTrak™ Karambit | Doppler (Factory New)", 20, "★ StatTrak™ Karam..."},
+		{"кириллица", "Наклейка | Легенды Кёльна", 12, "Наклейка ..."},
+	}
+	b := &Bot{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.truncateString(tt.s, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("%q, ожидалось %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("%q — неверный UTF-8", got)
+			}
+		})
+	}
+}
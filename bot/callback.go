@@ -0,0 +1,272 @@
+package bot
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"buff-youpin-checker/config"
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Формат callback_data: "<версия>|<действие>|<арг1>|<арг2>..."
+// При несовместимом изменении аргументов действия повышаем версию —
+// кнопки из старых сообщений будут распознаны как устаревшие.
+const (
+	callbackVersion   = "1"
+	callbackSeparator = "|"
+	maxCallbackData   = 64 // ограничение Telegram в байтах
+)
+
+// Действия кнопок
+const (
+	actionTopMenu      = "top"
+	actionCategory     = "cat"
+	actionItem         = "item"
+	actionSearchPage   = "srch"
+	actionLanguage     = "lang"
+	actionBudget       = "bdg"
+	actionBudgetCustom = "bdgc"
+	actionBudgetNew    = "bdgn"
+)
+
+var (
+	errStaleCallback     = errors.New("stale callback")
+	errMalformedCallback = errors.New("malformed callback")
+)
+
+type callbackPayload struct {
+	Action string
+	Args   []string
+	From   int64 // пользователь, нажавший кнопку
+}
+
+// Строковый аргумент по индексу
+func (p callbackPayload) String(i int) (string, error) {
+	if i >= len(p.Args) || p.Args[i] == "" {
+		return "", errMalformedCallback
+	}
+	return p.Args[i], nil
+}
+
+// Целочисленный аргумент по индексу
+func (p callbackPayload) Int(i int) (int, error) {
+	s, err := p.String(i)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errMalformedCallback
+	}
+	return n, nil
+}
+
+// Кодирование callback_data. Аргументы не должны содержать разделитель,
+// а результат — превышать 64 байта; иначе остается только действие,
+// и нажатие будет обработано как некорректное.
+func encodeCallback(action string, args ...interface{}) string {
+	parts := []string{callbackVersion, action}
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		default:
+			i18n.Logf("log.bot.callback_encode_failed", action, arg)
+			return callbackVersion + callbackSeparator + action
+		}
+		if strings.Contains(s, callbackSeparator) {
+			i18n.Logf("log.bot.callback_encode_failed", action, arg)
+			return callbackVersion + callbackSeparator + action
+		}
+		parts = append(parts, s)
+	}
+
+	data := strings.Join(parts, callbackSeparator)
+	if len(data) > maxCallbackData {
+		i18n.Logf("log.bot.callback_encode_failed", action, data)
+		return callbackVersion + callbackSeparator + action
+	}
+	return data
+}
+
+func decodeCallback(data string) (callbackPayload, error) {
+	parts := strings.Split(data, callbackSeparator)
+	if len(parts) < 2 || parts[1] == "" {
+		// Сюда же попадают кнопки старого формата (cat_..., item_...)
+		return callbackPayload{}, errStaleCallback
+	}
+	if parts[0] != callbackVersion {
+		return callbackPayload{}, errStaleCallback
+	}
+	return callbackPayload{Action: parts[1], Args: parts[2:]}, nil
+}
+
+// Кнопка с закодированным действием
+func callbackButton(text, action string, args ...interface{}) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(text, encodeCallback(action, args...))
+}
+
+// Кнопка, повторяющая ранее разобранное действие
+func (p callbackPayload) button(text string) tgbotapi.InlineKeyboardButton {
+	args := make([]interface{}, len(p.Args))
+	for i, arg := range p.Args {
+		args[i] = arg
+	}
+	return callbackButton(text, p.Action, args...)
+}
+
+// origin — сообщение с нажатой кнопкой; экраны навигации редактируют его
+type callbackHandler func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error
+
+// Маршрутизатор нажатий на inline-кнопки
+type callbackRouter struct {
+	handlers map[string]callbackHandler
+}
+
+func newCallbackRouter() *callbackRouter {
+	return &callbackRouter{handlers: make(map[string]callbackHandler)}
+}
+
+func (r *callbackRouter) Handle(action string, h callbackHandler) {
+	r.handlers[action] = h
+}
+
+func (r *callbackRouter) dispatch(origin *tgbotapi.Message, from int64, data string) error {
+	p, err := decodeCallback(data)
+	if err != nil {
+		return err
+	}
+	p.From = from
+
+	h, ok := r.handlers[p.Action]
+	if !ok {
+		return errStaleCallback
+	}
+	return h(origin.Chat.ID, origin, p)
+}
+
+// Регистрация обработчиков всех кнопок бота
+func (b *Bot) registerCallbacks() {
+	r := newCallbackRouter()
+
+	r.Handle(actionTopMenu, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		b.sendTopItems(chatID, origin)
+		return nil
+	})
+
+	// cat|<категория>|<страница>
+	r.Handle(actionCategory, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		category, err := p.String(0)
+		if err != nil {
+			return err
+		}
+		page := 1
+		if len(p.Args) > 1 {
+			if page, err = p.Int(1); err != nil {
+				return err
+			}
+		}
+		b.sendTopItemsByCategory(chatID, origin, category, page)
+		return nil
+	})
+
+	// item|<id>[|<действие кнопки "назад">|<аргументы>...]
+	r.Handle(actionItem, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		itemID, err := p.Int(0)
+		if err != nil {
+			return err
+		}
+		back := callbackPayload{Action: actionTopMenu}
+		if len(p.Args) > 1 {
+			back = callbackPayload{Action: p.Args[1], Args: p.Args[2:]}
+		}
+		b.sendItemDetails(chatID, origin, itemID, back)
+		return nil
+	})
+
+	// srch|<страница>
+	r.Handle(actionSearchPage, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		page, err := p.Int(0)
+		if err != nil {
+			return err
+		}
+		b.sendSearchPage(chatID, origin, page)
+		return nil
+	})
+
+	// lang|<код>
+	r.Handle(actionLanguage, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		code, err := p.String(0)
+		if err != nil {
+			return err
+		}
+		if _, ok := i18n.Parse(code); !ok {
+			return errMalformedCallback
+		}
+		b.setLanguage(chatID, p.From, code)
+		return nil
+	})
+
+	// bdg|<сумма>[|<уровень риска>]
+	r.Handle(actionBudget, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		amount, err := p.Int(0)
+		if err != nil {
+			return err
+		}
+		if amount < 1000 || amount > 10000000 {
+			return errMalformedCallback
+		}
+		risk := config.RiskMedium
+		if len(p.Args) > 1 {
+			risk = p.Args[1]
+			if risk != config.RiskLow && risk != config.RiskMedium && risk != config.RiskHigh {
+				return errMalformedCallback
+			}
+		}
+		b.clearState(chatID)
+		b.sendBudgetResults(chatID, float64(amount), risk)
+		return nil
+	})
+
+	r.Handle(actionBudgetCustom, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		b.setState(chatID, stateAwaitingBudget, "")
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "budget.custom_prompt")))
+		return nil
+	})
+
+	r.Handle(actionBudgetNew, func(chatID int64, origin *tgbotapi.Message, p callbackPayload) error {
+		b.sendBudgetCalculator(chatID)
+		return nil
+	})
+
+	b.callbacks = r
+}
+
+func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
+	lang := b.lang(callback.From.ID)
+
+	// Кнопки во inline-сообщениях не привязаны к чату
+	if callback.Message == nil {
+		b.request(tgbotapi.NewCallbackWithAlert(callback.ID, i18n.T(lang, "callback.stale")))
+		return
+	}
+
+	err := b.callbacks.dispatch(callback.Message, callback.From.ID, callback.Data)
+	switch {
+	case err == nil:
+		// Отвечаем на callback чтобы убрать "часики"
+		b.request(tgbotapi.NewCallback(callback.ID, ""))
+	case errors.Is(err, errStaleCallback):
+		b.request(tgbotapi.NewCallbackWithAlert(callback.ID, i18n.T(lang, "callback.stale")))
+	default:
+		i18n.Logf("log.bot.callback_malformed", callback.Data, err)
+		b.request(tgbotapi.NewCallbackWithAlert(callback.ID, i18n.T(lang, "callback.malformed")))
+	}
+}
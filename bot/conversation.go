@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"buff-youpin-checker/config"
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Состояния диалога: чего бот ждет от пользователя в свободном тексте
+const (
+	stateAwaitingBudget = "budget"
+	stateAwaitingTrade  = "trade" // в data хранится сторона сделки: BUY или SELL
+	stateAwaitingSearch = "search"
+)
+
+// Сколько бот ждет ответа, прежде чем забыть о вопросе
+const conversationTimeout = 10 * time.Minute
+
+// Запоминаем, что ждем ответ от чата
+func (b *Bot) setState(chatID int64, state, data string) {
+	if err := b.db.SetChatState(chatID, state, data, time.Now().Add(conversationTimeout)); err != nil {
+		i18n.Logf("log.bot.state_save_failed", chatID, err)
+	}
+}
+
+func (b *Bot) clearState(chatID int64) {
+	if err := b.db.ClearChatState(chatID); err != nil {
+		i18n.Logf("log.bot.state_save_failed", chatID, err)
+	}
+}
+
+// Текущее состояние чата; истекшие состояния удаляются
+func (b *Bot) currentState(chatID int64) (state, data string) {
+	s, err := b.db.GetChatState(chatID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			i18n.Logf("log.bot.state_load_failed", chatID, err)
+		}
+		return "", ""
+	}
+
+	if time.Now().After(s.ExpiresAt) {
+		b.clearState(chatID)
+		return "", ""
+	}
+	return s.State, s.Data
+}
+
+// Обработка свободного текста в зависимости от состояния диалога
+func (b *Bot) handleText(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	lang := b.lang(chatID)
+	text := strings.TrimSpace(message.Text)
+	if text == "" {
+		return
+	}
+
+	state, data := b.currentState(chatID)
+	switch state {
+	case stateAwaitingBudget:
+		budget, err := strconv.ParseFloat(strings.ReplaceAll(text, " ", ""), 64)
+		if err != nil || budget <= 0 {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.custom_prompt")))
+			return
+		}
+		if budget < 1000 {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.min")))
+			return
+		}
+		if budget > 10000000 {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.max")))
+			return
+		}
+		b.clearState(chatID)
+		b.sendBudgetResults(chatID, budget, config.RiskMedium)
+
+	case stateAwaitingTrade:
+		// При ошибке ввода состояние сохраняется, чтобы можно было исправить строку
+		if b.recordPortfolioTrade(chatID, message.From.ID, data, text) {
+			b.clearState(chatID)
+		}
+
+	case stateAwaitingSearch:
+		b.clearState(chatID)
+		b.handleSearch(chatID, text)
+
+	default:
+		// Число вне калькулятора — скорее всего бюджет; подсказываем команду
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "budget.use_command")))
+			return
+		}
+		// Любой другой текст считаем поисковым запросом
+		b.handleSearch(chatID, text)
+	}
+}
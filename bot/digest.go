@@ -0,0 +1,372 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // часовые пояса пользователей не зависят от системной базы
+
+	"buff-youpin-checker/analyzer"
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultDigestTimezone   = "Europe/Moscow"
+	digestBatchSize         = 100 // подписок за одну проверку
+	digestMoversPerCategory = 3
+	digestBuyLimit          = 10
+	digestRetryDelay        = 15 * time.Minute
+)
+
+var weekdays = map[string]time.Weekday{
+	"mon": time.Monday, "monday": time.Monday, "пн": time.Monday, "понедельник": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "вт": time.Tuesday, "вторник": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "ср": time.Wednesday, "среда": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "чт": time.Thursday, "четверг": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "пт": time.Friday, "пятница": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "сб": time.Saturday, "суббота": time.Saturday,
+	"sun": time.Sunday, "sunday": time.Sunday, "вс": time.Sunday, "воскресенье": time.Sunday,
+}
+
+// Обработка /subscribe [daily|weekly] [день недели] ЧЧ:ММ [часовой пояс]
+func (b *Bot) handleSubscribe(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	if strings.TrimSpace(args) == "" {
+		sub, err := b.db.GetDigestSubscription(chatID)
+		if errors.Is(err, sql.ErrNoRows) {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "subscribe.none")+"\n\n"+i18n.T(lang, "subscribe.usage")))
+			return
+		}
+		if err != nil {
+			i18n.Logf("log.digest.load_failed", err)
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.try_later")))
+			return
+		}
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "subscribe.status",
+			b.describeSchedule(lang, sub), formatDigestTime(sub.NextRunAt, sub.Timezone))))
+		return
+	}
+
+	sub, key := parseSubscription(chatID, args)
+	if key != "" {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, key)+"\n\n"+i18n.T(lang, "subscribe.usage")))
+		return
+	}
+
+	next, err := nextDigestRun(sub, time.Now())
+	if err != nil {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "subscribe.bad_timezone")))
+		return
+	}
+	sub.NextRunAt = next
+
+	if err := b.db.SaveDigestSubscription(sub); err != nil {
+		i18n.Logf("log.digest.save_failed", chatID, err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.try_later")))
+		return
+	}
+
+	b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "subscribe.saved",
+		b.describeSchedule(lang, sub), formatDigestTime(sub.NextRunAt, sub.Timezone))))
+}
+
+func (b *Bot) handleUnsubscribe(chatID int64) {
+	lang := b.lang(chatID)
+
+	deleted, err := b.db.DeleteDigestSubscription(chatID)
+	if err != nil {
+		i18n.Logf("log.digest.save_failed", chatID, err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.try_later")))
+		return
+	}
+	if !deleted {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "subscribe.none")))
+		return
+	}
+	b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "unsubscribe.done")))
+}
+
+// Разбор аргументов /subscribe; при ошибке возвращается ключ сообщения
+func parseSubscription(chatID int64, args string) (*database.DigestSubscription, string) {
+	fields := strings.Fields(strings.ToLower(args))
+	sub := &database.DigestSubscription{ChatID: chatID, Frequency: "daily", Timezone: defaultDigestTimezone}
+
+	if len(fields) > 0 && (fields[0] == "daily" || fields[0] == "weekly") {
+		sub.Frequency = fields[0]
+		fields = fields[1:]
+	}
+
+	if sub.Frequency == "weekly" {
+		if len(fields) == 0 {
+			return nil, "subscribe.bad_weekday"
+		}
+		weekday, ok := weekdays[fields[0]]
+		if !ok {
+			return nil, "subscribe.bad_weekday"
+		}
+		sub.Weekday = weekday
+		fields = fields[1:]
+	}
+
+	if len(fields) == 0 {
+		return nil, "subscribe.bad_time"
+	}
+	sendTime, err := time.Parse("15:04", fields[0])
+	if err != nil {
+		return nil, "subscribe.bad_time"
+	}
+	sub.SendTime = sendTime.Format("15:04")
+	fields = fields[1:]
+
+	if len(fields) > 0 {
+		// Названия поясов чувствительны к регистру: берем из исходной строки
+		original := strings.Fields(args)
+		sub.Timezone = original[len(original)-len(fields)]
+		if _, err := time.LoadLocation(sub.Timezone); err != nil {
+			return nil, "subscribe.bad_timezone"
+		}
+	}
+
+	return sub, ""
+}
+
+// Ближайшее время отправки строго после after
+func nextDigestRun(sub *database.DigestSubscription, after time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sendTime, err := time.Parse("15:04", sub.SendTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := after.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(),
+		sendTime.Hour(), sendTime.Minute(), 0, 0, loc)
+	for !next.After(after) || (sub.Frequency == "weekly" && next.Weekday() != sub.Weekday) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1,
+			sendTime.Hour(), sendTime.Minute(), 0, 0, loc)
+	}
+	return next, nil
+}
+
+func (b *Bot) describeSchedule(lang i18n.Lang, sub *database.DigestSubscription) string {
+	if sub.Frequency == "weekly" {
+		return i18n.T(lang, "subscribe.weekly", i18n.T(lang, fmt.Sprintf("weekday.%d", sub.Weekday)), sub.SendTime, sub.Timezone)
+	}
+	return i18n.T(lang, "subscribe.daily", sub.SendTime, sub.Timezone)
+}
+
+func formatDigestTime(t time.Time, timezone string) string {
+	if loc, err := time.LoadLocation(timezone); err == nil {
+		t = t.In(loc)
+	}
+	return t.Format("02.01.2006 15:04")
+}
+
+// Данные рынка, общие для всех дайджестов одной проверки
+type digestMarket struct {
+	movers map[string]map[string]*analyzer.CategoryMovers // по частоте
+	buys   []analyzer.ItemTrend
+}
+
+// Задача рассылки дайджестов, которым подошло время (запускается
+// планировщиком раз в минуту). Расписание хранится в БД, поэтому
+// пропущенные за время простоя дайджесты отправляются после запуска.
+func (b *Bot) SendDueDigests(ctx context.Context, progress func(done, total int)) error {
+	now := time.Now()
+	subs, err := b.db.GetDueDigestSubscriptions(now, digestBatchSize)
+	if err != nil {
+		return fmt.Errorf("load subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	market := &digestMarket{movers: make(map[string]map[string]*analyzer.CategoryMovers)}
+	if market.buys, err = b.analyzer.GetBuyRecommendations(digestBuyLimit * 5); err != nil {
+		return fmt.Errorf("load recommendations: %w", err)
+	}
+
+	// Дайджесты отправляются по одному через общую очередь, поэтому
+	// лимиты Telegram соблюдаются и ответы на команды не ждут рассылку
+	sent := 0
+	for i, sub := range subs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, ok := market.movers[sub.Frequency]; !ok {
+			period := 24 * time.Hour
+			if sub.Frequency == "weekly" {
+				period = 7 * 24 * time.Hour
+			}
+			movers, err := b.analyzer.GetCategoryMovers(now.Add(-period), digestMoversPerCategory)
+			if err != nil {
+				return fmt.Errorf("load movers: %w", err)
+			}
+			market.movers[sub.Frequency] = movers
+		}
+
+		if b.sendDigest(sub, market, now) {
+			sent++
+		}
+		if progress != nil {
+			progress(i+1, len(subs))
+		}
+	}
+
+	i18n.Logf("log.digest.sent", sent, len(subs))
+	return nil
+}
+
+// Отправка одного дайджеста; возвращает true при успехе
+func (b *Bot) sendDigest(sub *database.DigestSubscription, market *digestMarket, now time.Time) bool {
+	chatID := sub.ChatID
+	lang := b.lang(chatID)
+
+	next, err := nextDigestRun(sub, now)
+	if err != nil {
+		i18n.Logf("log.digest.send_failed", chatID, err)
+		b.db.DeleteDigestSubscription(chatID)
+		return false
+	}
+
+	text := newMessage(lang)
+	if sub.Frequency == "weekly" {
+		text.T("digest.title.weekly", formatDigestTime(now, sub.Timezone))
+	} else {
+		text.T("digest.title.daily", formatDigestTime(now, sub.Timezone))
+	}
+
+	b.writeDigestMovers(text, lang, market.movers[sub.Frequency])
+	buyIDs := b.writeDigestBuys(text, lang, sub, market.buys)
+	portfolioValue := b.writeDigestPortfolio(text, lang, sub)
+	text.T("digest.footer")
+
+	// Дайджест считается отправленным после первой части: если следующая
+	// не дошла, повтор с начала прислал бы пользователю дубликаты
+	for i, part := range text.Split(maxMessageLength) {
+		msg := tgbotapi.NewMessage(chatID, part)
+		msg.ParseMode = parseModeHTML
+		if _, err := b.send(msg); err != nil {
+			b.handleDigestError(sub, err, now, i > 0)
+			return i > 0
+		}
+		if i == 0 {
+			if err := b.db.MarkDigestSent(chatID, now, next, buyIDs, portfolioValue); err != nil {
+				i18n.Logf("log.digest.save_failed", chatID, err)
+			}
+		}
+	}
+	return true
+}
+
+// Ошибка отправки; delivered — часть дайджеста уже дошла, и следующий
+// запуск назначен, поэтому повтор не нужен
+func (b *Bot) handleDigestError(sub *database.DigestSubscription, err error, now time.Time, delivered bool) {
+	i18n.Logf("log.digest.send_failed", sub.ChatID, err)
+
+	// Бот заблокирован или удален из чата — подписка больше не нужна
+	if errors.Is(err, errChatBlocked) {
+		b.db.DeleteDigestSubscription(sub.ChatID)
+		return
+	}
+
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && undeliverable(apiErr) {
+		b.db.DeleteDigestSubscription(sub.ChatID)
+		return
+	}
+	if delivered {
+		return
+	}
+
+	if apiErr != nil && apiErr.RetryAfter > 0 {
+		b.db.RescheduleDigest(sub.ChatID, now.Add(time.Duration(apiErr.RetryAfter)*time.Second))
+		return
+	}
+
+	if err := b.db.RescheduleDigest(sub.ChatID, now.Add(digestRetryDelay)); err != nil {
+		log.Printf("reschedule error: %v", err)
+	}
+}
+
+func (b *Bot) writeDigestMovers(text *messageBuilder, lang i18n.Lang, movers map[string]*analyzer.CategoryMovers) {
+	text.T("digest.movers")
+	if len(movers) == 0 {
+		text.T("digest.no_movers")
+		return
+	}
+
+	categories := make([]string, 0, len(movers))
+	for category := range movers {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		m := movers[category]
+		// Категория целиком — один блок, чтобы не разрывать ее между сообщениями
+		block := newMessage(lang).
+			Textf("\n%s <b>%s</b>\n", b.getCategoryEmoji(category), b.getCategoryLabel(lang, category))
+		for _, move := range m.Gainers {
+			block.T("digest.gainer", move.MarketName, move.Change, move.CurrentPrice)
+		}
+		for _, move := range m.Losers {
+			block.T("digest.loser", move.MarketName, move.Change, move.CurrentPrice)
+		}
+		text.Raw(block.String())
+	}
+}
+
+// Новые рекомендации BUY относительно прошлого дайджеста; возвращает текущий набор
+func (b *Bot) writeDigestBuys(text *messageBuilder, lang i18n.Lang, sub *database.DigestSubscription, buys []analyzer.ItemTrend) []int64 {
+	previous := make(map[int64]bool, len(sub.LastBuyItems))
+	for _, id := range sub.LastBuyItems {
+		previous[id] = true
+	}
+
+	current := make([]int64, 0, len(buys))
+	var fresh []analyzer.ItemTrend
+	for _, trend := range buys {
+		current = append(current, int64(trend.ItemID))
+		if !previous[int64(trend.ItemID)] && len(fresh) < digestBuyLimit {
+			fresh = append(fresh, trend)
+		}
+	}
+
+	text.T("digest.new_buys")
+	if len(fresh) == 0 {
+		text.T("digest.no_new_buys")
+	}
+	for _, trend := range fresh {
+		text.T("digest.buy_line", trend.MarketName, trend.TrendScore, trend.CurrentPrice)
+	}
+	return current
+}
+
+// Стоимость портфеля и изменение с прошлого дайджеста
+func (b *Bot) writeDigestPortfolio(text *messageBuilder, lang i18n.Lang, sub *database.DigestSubscription) float64 {
+	// В личных чатах ID чата совпадает с ID пользователя
+	summary, err := b.portfolio.Summary(sub.ChatID)
+	if err != nil || len(summary.Lots) == 0 {
+		return 0
+	}
+
+	text.T("digest.portfolio", summary.MarketValue)
+	if sub.LastPortfolio.Valid && sub.LastPortfolio.Float64 > 0 {
+		delta := summary.MarketValue - sub.LastPortfolio.Float64
+		text.T("digest.portfolio_delta", delta, delta/sub.LastPortfolio.Float64*100)
+	}
+	return summary.MarketValue
+}
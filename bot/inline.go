@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"buff-youpin-checker/analyzer"
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram принимает не более 50 результатов на один ответ
+const inlineResultsPerPage = 20
+
+// Обработка inline-запросов вида "@bot ak redline ft" из любого чата
+func (b *Bot) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	lang := b.lang(query.From.ID)
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		CacheTime:     60,
+		// Карточки на языке пользователя: общий кэш показал бы их другим
+		IsPersonal: true,
+		Results:    []interface{}{},
+	}
+
+	text := strings.TrimSpace(query.Query)
+	if text == "" {
+		answer.SwitchPMText = i18n.T(lang, "inline.open_bot")
+		answer.SwitchPMParameter = "inline"
+		if _, err := b.request(answer); err != nil {
+			log.Printf("inline answer error: %v", err)
+		}
+		return
+	}
+
+	// Offset - номер следующей страницы, который Telegram возвращает при прокрутке
+	offset, _ := strconv.Atoi(query.Offset)
+	if offset < 0 {
+		offset = 0
+	}
+
+	trends, total, err := b.analyzer.SearchItemTrends(text, inlineResultsPerPage, offset)
+	if err != nil {
+		i18n.Logf("log.inline.search_failed", text, err)
+		return
+	}
+
+	for _, trend := range trends {
+		article := tgbotapi.NewInlineQueryResultArticle(
+			strconv.Itoa(trend.ItemID), trend.MarketName, b.formatItemCard(lang, trend))
+		article.Description = b.formatItemSummary(lang, trend)
+		answer.Results = append(answer.Results, article)
+	}
+
+	if next := offset + len(trends); next < total {
+		answer.NextOffset = strconv.Itoa(next)
+	}
+
+	if _, err := b.request(answer); err != nil {
+		log.Printf("inline answer error: %v", err)
+	}
+}
+
+// Короткая строка под названием в списке inline-результатов
+func (b *Bot) formatItemSummary(lang i18n.Lang, trend analyzer.ItemTrend) string {
+	summary := i18n.T(lang, "inline.summary", trend.CurrentPrice, trend.WeekChange)
+	if trend.Recommendation != "" {
+		summary += fmt.Sprintf(" | %s %s", b.getRecommendationEmoji(trend.Recommendation), trend.Recommendation)
+	}
+	return summary
+}
+
+// Карточка предмета, которая публикуется в чат при выборе inline-результата
+func (b *Bot) formatItemCard(lang i18n.Lang, trend analyzer.ItemTrend) string {
+	text := fmt.Sprintf("%s %s\n", b.getCategoryEmoji(trend.Category), trend.MarketName)
+	text += fmt.Sprintf("📂 %s\n\n", b.getCategoryLabel(lang, trend.Category))
+	text += i18n.T(lang, "item.price", trend.CurrentPrice)
+	text += i18n.T(lang, "inline.week_change", trend.WeekChange)
+
+	if trend.Recommendation != "" {
+		text += i18n.T(lang, "item.score", trend.TrendScore)
+		text += i18n.T(lang, "inline.recommendation",
+			b.getRecommendationEmoji(trend.Recommendation), trend.Recommendation)
+		text += fmt.Sprintf("💡 %s\n", b.getInvestmentAdvice(lang, trend))
+	} else {
+		text += i18n.T(lang, "inline.not_analyzed")
+	}
+
+	text += fmt.Sprintf("\n🤖 @%s", b.api.Self.UserName)
+	return text
+}
@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/jobs"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Как часто обновлять сообщение с прогрессом задачи
+const jobProgressInterval = 5 * time.Second
+
+// Обработка /analyze
+func (b *Bot) runAnalysis(chatID int64) {
+	b.runJob(chatID, jobs.Analysis, "analyze", func(lang i18n.Lang) string {
+		return i18n.T(lang, "analyze.done")
+	})
+}
+
+// Обработка /collect: внеочередной сбор цен
+func (b *Bot) runCollection(chatID int64) {
+	b.runJob(chatID, jobs.Collection, "collect", func(lang i18n.Lang) string {
+		run, err := b.db.GetLastCollectionRun()
+		if err != nil {
+			return i18n.T(lang, "collect.done_short")
+		}
+		return i18n.T(lang, "collect.done", run.ItemsReceived, run.ItemsStored, run.ItemsFailed)
+	})
+}
+
+// Запуск задачи или присоединение к уже идущей. Сообщение о статусе
+// обновляется с процентом выполнения, а по завершении заменяется итогом.
+// Ключи сообщений: <prefix>.started, .joined, .progress, .failed.
+func (b *Bot) runJob(chatID int64, name, prefix string, doneText func(lang i18n.Lang) string) {
+	lang := b.lang(chatID)
+
+	run, started, err := b.jobs.Trigger(name)
+	if errors.Is(err, jobs.ErrNotLeader) {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "jobs.not_leader")))
+		return
+	}
+	if err != nil {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, prefix+".failed", err.Error())))
+		return
+	}
+
+	text := i18n.T(lang, prefix+".started")
+	if !started {
+		text = i18n.T(lang, prefix+".joined", run.Progress())
+	}
+	status, err := b.send(tgbotapi.NewMessage(chatID, text))
+	if err != nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(jobProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-run.Done():
+				final := doneText(lang)
+				if err := run.Err(); err != nil {
+					final = i18n.T(lang, prefix+".failed", err.Error())
+				}
+				if _, err := b.request(tgbotapi.NewEditMessageText(chatID, status.MessageID, final)); err != nil {
+					b.send(tgbotapi.NewMessage(chatID, final))
+				}
+				return
+			case <-ticker.C:
+				b.request(tgbotapi.NewEditMessageText(chatID, status.MessageID,
+					i18n.T(lang, prefix+".progress", run.Progress())))
+			}
+		}
+	}()
+}
+
+// Обработка /jobs: расписание задач и их последние запуски
+func (b *Bot) sendJobs(chatID int64) {
+	lang := b.lang(chatID)
+
+	states, err := b.db.GetJobStates()
+	if err != nil {
+		i18n.Logf("log.scheduler.state_failed", err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "common.data_error")))
+		return
+	}
+	if len(states) == 0 {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "jobs.empty")))
+		return
+	}
+
+	names := make([]string, 0, len(states))
+	for name := range states {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return i18n.T(lang, "stats.never")
+		}
+		return t.Local().Format("02.01.2006 15:04")
+	}
+
+	text := newMessage(lang).T("jobs.title")
+	for _, name := range names {
+		st := states[name]
+		text.T("jobs.item", name, st.Schedule, formatTime(st.NextRunAt))
+		if run := b.jobs.Current(name); run != nil {
+			text.T("jobs.running", run.Progress())
+			continue
+		}
+		if st.LastStartedAt.IsZero() {
+			text.T("jobs.never_run")
+			continue
+		}
+		duration := st.LastFinishedAt.Sub(st.LastStartedAt).Round(time.Second)
+		if st.LastError != "" {
+			text.T("jobs.last_failed", formatTime(st.LastStartedAt), duration, st.LastError)
+		} else {
+			text.T("jobs.last_ok", formatTime(st.LastStartedAt), duration)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = parseModeHTML
+	b.send(msg)
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Язык пользователя: кэш, затем настройки в БД, затем язык по умолчанию.
+// В личных чатах ID чата совпадает с ID пользователя.
+func (b *Bot) lang(userID int64) i18n.Lang {
+	b.langMu.RLock()
+	lang, ok := b.langs[userID]
+	b.langMu.RUnlock()
+	if ok {
+		return lang
+	}
+
+	lang = i18n.Default
+	if code, err := b.db.GetUserLanguage(userID); err == nil {
+		if parsed, ok := i18n.Parse(code); ok {
+			lang = parsed
+		}
+	}
+
+	b.langMu.Lock()
+	b.langs[userID] = lang
+	b.langMu.Unlock()
+	return lang
+}
+
+// Обработка /language
+func (b *Bot) sendLanguageMenu(chatID int64) {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range i18n.Supported() {
+		row = append(row, callbackButton(
+			i18n.T(lang, "language.name"), actionLanguage, string(lang)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "language.choose"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	b.send(msg)
+}
+
+// Язык сохраняется для пользователя userID, а не для чата: он же
+// используется в inline-режиме. Сообщения в группе бот пишет на языке
+// по умолчанию, поэтому выбор в группе меняет только личные ответы.
+func (b *Bot) setLanguage(chatID, userID int64, code string) {
+	lang, ok := i18n.Parse(code)
+	if !ok {
+		return
+	}
+
+	if err := b.db.SetUserLanguage(userID, string(lang)); err != nil {
+		i18n.Logf("log.bot.language_save_failed", userID, err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(b.lang(userID), "common.try_later")))
+		return
+	}
+
+	b.langMu.Lock()
+	b.langs[userID] = lang
+	b.langMu.Unlock()
+
+	if chatID != userID {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "language.changed_group")))
+		return
+	}
+	b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "language.changed")))
+	b.sendWelcomeMessage(chatID)
+}
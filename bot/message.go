@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"buff-youpin-checker/i18n"
+)
+
+// Все сообщения с разметкой отправляются в режиме HTML: шаблоны каталога
+// содержат теги <b>/<i>, а названия предметов и прочие данные экранируются.
+const parseModeHTML = "HTML"
+
+// Ограничения Telegram на длину после разбора разметки (в единицах UTF-16)
+const (
+	maxMessageLength = 4096
+	maxCaptionLength = 1024
+)
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// Экранирование произвольного текста для HTML-разметки
+func escapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// Длина текста так, как ее считает Telegram: без тегов, с раскрытыми
+// сущностями, в единицах UTF-16
+func visibleLength(markup string) int {
+	text := html.UnescapeString(htmlTag.ReplaceAllString(markup, ""))
+	return len(utf16.Encode([]rune(text)))
+}
+
+// Построитель HTML-сообщения. Сообщение собирается из блоков, каждый блок
+// содержит законченную разметку, поэтому разбиение по границам блоков
+// не разрывает теги и сущности.
+type messageBuilder struct {
+	lang   i18n.Lang
+	blocks []string
+}
+
+func newMessage(lang i18n.Lang) *messageBuilder {
+	return &messageBuilder{lang: lang}
+}
+
+// Шаблон из каталога; строковые аргументы экранируются
+func (m *messageBuilder) T(key string, args ...interface{}) *messageBuilder {
+	return m.Raw(i18n.T(m.lang, key, escapeArgs(args)...))
+}
+
+// Форматированная строка; формат считается разметкой, строковые аргументы экранируются
+func (m *messageBuilder) Textf(format string, args ...interface{}) *messageBuilder {
+	return m.Raw(fmt.Sprintf(format, escapeArgs(args)...))
+}
+
+// Простой текст без разметки
+func (m *messageBuilder) Text(s string) *messageBuilder {
+	return m.Raw(escapeHTML(s))
+}
+
+// Готовая разметка (например, уже отформатированный фрагмент)
+func (m *messageBuilder) Raw(markup string) *messageBuilder {
+	if markup != "" {
+		m.blocks = append(m.blocks, markup)
+	}
+	return m
+}
+
+func (m *messageBuilder) String() string {
+	return strings.Join(m.blocks, "")
+}
+
+func (m *messageBuilder) Len() int {
+	return visibleLength(m.String())
+}
+
+// Разбиение на сообщения не длиннее limit. Режем между блоками, а слишком
+// длинный блок — по строкам; строка, не влезающая целиком, отправляется
+// без разметки и режется по символам.
+func (m *messageBuilder) Split(limit int) []string {
+	var parts []string
+	var current strings.Builder
+	currentLen := 0
+
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+	}
+	add := func(piece string, length int) {
+		if currentLen+length > limit {
+			flush()
+		}
+		current.WriteString(piece)
+		currentLen += length
+	}
+
+	for _, block := range m.blocks {
+		length := visibleLength(block)
+		if length <= limit {
+			add(block, length)
+			continue
+		}
+
+		for _, line := range strings.SplitAfter(block, "\n") {
+			lineLen := visibleLength(line)
+			if lineLen <= limit {
+				add(line, lineLen)
+				continue
+			}
+			for _, chunk := range splitPlain(html.UnescapeString(htmlTag.ReplaceAllString(line, "")), limit) {
+				add(escapeHTML(chunk), len(utf16.Encode([]rune(chunk))))
+			}
+		}
+	}
+	flush()
+
+	return parts
+}
+
+// Разбиение простого текста по границам символов
+func splitPlain(text string, limit int) []string {
+	var chunks []string
+	var current []rune
+	currentLen := 0
+	for _, r := range text {
+		size := len(utf16.Encode([]rune{r}))
+		if currentLen+size > limit {
+			chunks = append(chunks, string(current))
+			current = current[:0]
+			currentLen = 0
+		}
+		current = append(current, r)
+		currentLen += size
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, string(current))
+	}
+	return chunks
+}
+
+func escapeArgs(args []interface{}) []interface{} {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			escaped[i] = escapeHTML(s)
+		} else {
+			escaped[i] = arg
+		}
+	}
+	return escaped
+}
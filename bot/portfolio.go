@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/portfolio"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Сколько лотов показывать в одном сообщении
+const maxPortfolioLots = 20
+
+// Обработка /buy и /sell. Без аргументов бот просит ввести сделку
+// следующим сообщением.
+func (b *Bot) startPortfolioTrade(message *tgbotapi.Message, side string) {
+	chatID := message.Chat.ID
+	args := strings.TrimSpace(message.CommandArguments())
+	if args == "" {
+		b.setState(chatID, stateAwaitingTrade, side)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "portfolio.entry_prompt")))
+		return
+	}
+
+	b.recordPortfolioTrade(chatID, message.From.ID, side, args)
+}
+
+// Разбор и запись сделки "Название; количество; цена; [площадка]; [дата]".
+// Возвращает true, если сделка сохранена.
+func (b *Bot) recordPortfolioTrade(chatID, userID int64, side, args string) bool {
+	lang := b.lang(chatID)
+	usage := i18n.T(lang, "portfolio.usage")
+
+	parts := strings.Split(args, ";")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	if len(parts) < 3 || parts[0] == "" {
+		b.send(tgbotapi.NewMessage(chatID, usage))
+		return false
+	}
+
+	quantity, err := strconv.Atoi(parts[1])
+	if err != nil || quantity <= 0 {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.bad_quantity")+"\n\n"+usage))
+		return false
+	}
+
+	price, err := strconv.ParseFloat(strings.ReplaceAll(parts[2], ",", "."), 64)
+	if err != nil || price < 0 {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.bad_price")+"\n\n"+usage))
+		return false
+	}
+
+	venue := "market.csgo.com"
+	if len(parts) >= 4 && parts[3] != "" {
+		venue = parts[3]
+	}
+
+	// Дата сделки — день UTC, как у дневных цен
+	executedAt := time.Now().UTC()
+	if len(parts) >= 5 && parts[4] != "" {
+		executedAt, err = time.Parse("2006-01-02", parts[4])
+		if err != nil {
+			b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.bad_date")))
+			return false
+		}
+	}
+
+	tx, err := b.portfolio.Record(userID, parts[0], side, quantity, price, venue, executedAt)
+	switch {
+	case errors.Is(err, portfolio.ErrUnknownItem):
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.unknown_item")))
+		return false
+	case errors.Is(err, portfolio.ErrInsufficientQuantity):
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.insufficient")))
+		return false
+	case err != nil:
+		i18n.Logf("log.portfolio.record_failed", err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.save_failed")))
+		return false
+	}
+
+	action := i18n.T(lang, "portfolio.side.buy")
+	if tx.Side == "SELL" {
+		action = i18n.T(lang, "portfolio.side.sell")
+	}
+	text := i18n.T(lang, "portfolio.recorded",
+		action, tx.MarketName, tx.Quantity, tx.Price, tx.Price*float64(tx.Quantity),
+		tx.Venue, tx.ExecutedAt.Format("02.01.2006"))
+	b.send(tgbotapi.NewMessage(chatID, text))
+	return true
+}
+
+// Обработка /portfolio: сводка, лоты, распределение и график стоимости
+func (b *Bot) sendPortfolio(chatID, userID int64) {
+	lang := b.lang(chatID)
+
+	summary, err := b.portfolio.Summary(userID)
+	if err != nil {
+		i18n.Logf("log.portfolio.summary_failed", err)
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.error")))
+		return
+	}
+
+	if len(summary.Lots) == 0 {
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(lang, "portfolio.empty")+"\n\n"+i18n.T(lang, "portfolio.usage")))
+		return
+	}
+
+	text := i18n.T(lang, "portfolio.title")
+	text += i18n.T(lang, "portfolio.cost_basis", summary.CostBasis)
+	text += i18n.T(lang, "portfolio.market_value", summary.MarketValue)
+	text += i18n.T(lang, "portfolio.unrealized", formatPnL(summary.Unrealized, summary.CostBasis))
+	text += i18n.T(lang, "portfolio.realized", summary.Realized)
+
+	text += i18n.T(lang, "portfolio.lots")
+	for i, lot := range summary.Lots {
+		if i == maxPortfolioLots {
+			rest := len(summary.Lots) - maxPortfolioLots
+			text += i18n.T(lang, "portfolio.more_lots", i18n.N(lang, rest, "unit.lot"))
+			break
+		}
+		text += fmt.Sprintf("%d. %s %s\n", i+1, b.getCategoryEmoji(lot.Category), lot.MarketName)
+		text += i18n.T(lang, "portfolio.lot_line",
+			lot.BoughtAt.Format("02.01.2006"), lot.Venue, lot.OpenQuantity, lot.Quantity,
+			lot.BuyPrice, lot.CurrentPrice)
+		if lot.OpenQuantity > 0 {
+			text += i18n.T(lang, "portfolio.lot_unrealized",
+				formatPnL(lot.Unrealized, lot.BuyPrice*float64(lot.OpenQuantity)))
+		}
+		if lot.OpenQuantity < lot.Quantity {
+			text += i18n.T(lang, "portfolio.lot_realized", lot.Realized)
+		}
+	}
+
+	if len(summary.Allocation) > 0 {
+		text += i18n.T(lang, "portfolio.allocation")
+		for _, share := range summary.Allocation {
+			text += fmt.Sprintf("%s %s: %.2f ₽ (%.1f%%)\n", b.getCategoryEmoji(share.Category),
+				b.getCategoryLabel(lang, share.Category), share.Value, share.Share*100)
+		}
+	}
+
+	b.send(tgbotapi.NewMessage(chatID, text))
+
+	history, err := b.portfolio.ValueHistory(userID, 30)
+	if err != nil {
+		i18n.Logf("log.portfolio.history_failed", err)
+		return
+	}
+
+	png, err := b.charts.GeneratePortfolioChart(lang, history)
+	if err != nil {
+		// Истории меньше двух дней - графика пока нет
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "portfolio.png", Bytes: png})
+	photo.Caption = i18n.T(lang, "portfolio.chart_caption")
+	if _, e := b.send(photo); e != nil {
+		log.Printf("send error: %v", e)
+	}
+}
+
+// P&L в рублях и процентах от базы
+func formatPnL(pnl, base float64) string {
+	if base <= 0 {
+		return fmt.Sprintf("%+.2f ₽", pnl)
+	}
+	return fmt.Sprintf("%+.2f ₽ (%+.1f%%)", pnl, pnl/base*100)
+}
@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Экран навигации: текст или фото с подписью и клавиатурой.
+// Экраны показываются на месте исходного сообщения, если это возможно.
+type screen struct {
+	Text      string
+	ParseMode string
+	PhotoURL  string // пусто для текстового экрана
+	Keyboard  tgbotapi.InlineKeyboardMarkup
+}
+
+// Телеграм не умеет превращать текстовое сообщение в фото и обратно
+var errScreenKindChanged = errors.New("screen kind changed")
+
+// Показ экрана: редактируем origin, а если это невозможно — отправляем новое
+// сообщение. origin == nil означает ответ на команду, а не на кнопку.
+func (b *Bot) show(chatID int64, origin *tgbotapi.Message, s screen) {
+	if origin != nil {
+		err := b.editScreen(origin, s)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, errScreenKindChanged) {
+			log.Printf("edit error: %v", err)
+		}
+	}
+
+	if _, e := b.send(b.newScreenMessage(chatID, s)); e != nil {
+		log.Printf("send error: %v", e)
+		return
+	}
+
+	// Старый экран заменен новым сообщением — убираем его, чтобы не засорять чат
+	if origin != nil {
+		b.request(tgbotapi.NewDeleteMessage(chatID, origin.MessageID))
+	}
+}
+
+func (b *Bot) editScreen(origin *tgbotapi.Message, s screen) error {
+	chatID := origin.Chat.ID
+	hasPhoto := len(origin.Photo) > 0
+	if hasPhoto != (s.PhotoURL != "") {
+		return errScreenKindChanged
+	}
+
+	var edit tgbotapi.Chattable
+	if hasPhoto {
+		photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(s.PhotoURL))
+		photo.Caption = s.Text
+		photo.ParseMode = s.ParseMode
+		edit = tgbotapi.EditMessageMediaConfig{
+			BaseEdit: tgbotapi.BaseEdit{
+				ChatID:      chatID,
+				MessageID:   origin.MessageID,
+				ReplyMarkup: &s.Keyboard,
+			},
+			Media: photo,
+		}
+	} else {
+		msg := tgbotapi.NewEditMessageTextAndMarkup(chatID, origin.MessageID, s.Text, s.Keyboard)
+		msg.ParseMode = s.ParseMode
+		edit = msg
+	}
+
+	_, err := b.request(edit)
+	// Повторное нажатие на ту же кнопку — сообщение уже в нужном виде
+	if err != nil && strings.Contains(err.Error(), "message is not modified") {
+		return nil
+	}
+	return err
+}
+
+func (b *Bot) newScreenMessage(chatID int64, s screen) tgbotapi.Chattable {
+	if s.PhotoURL != "" {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(s.PhotoURL))
+		photo.Caption = s.Text
+		photo.ParseMode = s.ParseMode
+		photo.ReplyMarkup = s.Keyboard
+		return photo
+	}
+
+	msg := tgbotapi.NewMessage(chatID, s.Text)
+	msg.ParseMode = s.ParseMode
+	msg.ReplyMarkup = s.Keyboard
+	return msg
+}
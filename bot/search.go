@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const searchResultsPerPage = 8
+
+// Обработка /search. Без аргументов бот ждет запрос следующим сообщением.
+func (b *Bot) startSearch(chatID int64, text string) {
+	if strings.TrimSpace(text) == "" {
+		b.setState(chatID, stateAwaitingSearch, "")
+		b.send(tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "search.prompt")))
+		return
+	}
+
+	b.handleSearch(chatID, text)
+}
+
+// Обработка /search <текст> и обычного текста в чате
+func (b *Bot) handleSearch(chatID int64, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(b.lang(chatID), "search.prompt"))
+		b.send(msg)
+		return
+	}
+
+	// Запоминаем запрос, чтобы кнопки пагинации не зависели от лимита callback_data
+	b.searchMu.Lock()
+	b.searches[chatID] = text
+	b.searchMu.Unlock()
+
+	b.sendSearchPage(chatID, nil, 1)
+}
+
+func (b *Bot) sendSearchPage(chatID int64, origin *tgbotapi.Message, page int) {
+	lang := b.lang(chatID)
+
+	b.searchMu.Lock()
+	text, ok := b.searches[chatID]
+	b.searchMu.Unlock()
+	if !ok {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "search.expired"))
+		b.send(msg)
+		return
+	}
+
+	if page < 1 {
+		page = 1
+	}
+
+	results, total, err := b.analyzer.SearchItemTrends(text, searchResultsPerPage, (page-1)*searchResultsPerPage)
+	if err == nil && len(results) == 0 && total > 0 {
+		// Совпадений стало меньше, чем на момент показа кнопки, —
+		// показываем последнюю страницу
+		page = (total + searchResultsPerPage - 1) / searchResultsPerPage
+		results, total, err = b.analyzer.SearchItemTrends(text, searchResultsPerPage, (page-1)*searchResultsPerPage)
+	}
+	if err != nil {
+		i18n.Logf("log.search.failed", text, err)
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "search.error"))
+		b.send(msg)
+		return
+	}
+
+	if total == 0 {
+		msg := tgbotapi.NewMessage(chatID, i18n.T(lang, "search.nothing", text))
+		b.send(msg)
+		return
+	}
+
+	totalPages := (total + searchResultsPerPage - 1) / searchResultsPerPage
+	reply := i18n.T(lang, "search.title", text, i18n.N(lang, total, "unit.item"), page, totalPages)
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, r := range results {
+		index := (page-1)*searchResultsPerPage + i + 1
+		reply += fmt.Sprintf("%d. %s %s\n", index, b.getCategoryEmoji(r.Category), r.MarketName)
+		if r.Recommendation != "" {
+			reply += i18n.T(lang, "search.line",
+				b.getRecommendationEmoji(r.Recommendation), r.TrendScore, r.CurrentPrice, r.WeekChange)
+		} else {
+			reply += i18n.T(lang, "search.line_unanalyzed", r.CurrentPrice, r.WeekChange)
+		}
+
+		button := callbackButton(
+			fmt.Sprintf("📊 %s", b.truncateString(r.MarketName, 30)),
+			actionItem, r.ItemID, actionSearchPage, page)
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
+	}
+
+	var navButtons []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		navButtons = append(navButtons,
+			callbackButton(i18n.T(lang, "nav.prev"), actionSearchPage, page-1))
+	}
+	if page < totalPages {
+		navButtons = append(navButtons,
+			callbackButton(i18n.T(lang, "nav.next"), actionSearchPage, page+1))
+	}
+	if len(navButtons) > 0 {
+		keyboard = append(keyboard, navButtons)
+	}
+
+	b.show(chatID, origin, screen{Text: reply, Keyboard: tgbotapi.NewInlineKeyboardMarkup(keyboard...)})
+}
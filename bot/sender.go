@@ -0,0 +1,388 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/time/rate"
+)
+
+// Ограничения Telegram: около 30 сообщений в секунду на бота,
+// 1 в секунду в личный чат и 20 в минуту в группу
+const (
+	chatQueueSize     = 100
+	globalSendRate    = 25
+	privateChatBurst  = 3
+	groupChatInterval = 3 * time.Second
+	maxSendAttempts   = 5
+	sendBackoffBase   = time.Second
+	sendBackoffMax    = 30 * time.Second
+	sendDrainTimeout  = 5 * time.Second  // сколько дожидаться очередей при остановке
+	limiterSweepEvery = 10 * time.Minute // как часто удалять лимитеры простаивающих чатов
+)
+
+// Ответы Telegram, после которых в чат больше нельзя писать. Остальные
+// 403 (например, "not enough rights" в группе) зависят от прав и проходят
+// сами, поэтому чат из-за них не блокируется.
+var undeliverableErrors = []string{
+	"bot was blocked by the user",
+	"user is deactivated",
+	"bot was kicked",
+	"chat not found",
+}
+
+var (
+	// Чат отмечен как недоступный: бот заблокирован или удален из чата
+	errChatBlocked = errors.New("chat is blocked")
+	// В очереди чата слишком много неотправленных сообщений
+	errQueueFull = errors.New("chat send queue is full")
+	// Бот останавливается, новые запросы не принимаются
+	errSenderStopped = errors.New("sender is stopped")
+)
+
+type sendJob struct {
+	c      tgbotapi.Chattable
+	result chan sendResult
+}
+
+type sendResult struct {
+	resp *tgbotapi.APIResponse
+	err  error
+}
+
+// Исходящие запросы к Telegram с ограничением частоты и повторами.
+// У каждого чата своя очередь и своя горутина, поэтому ожидание лимита
+// группы или retry_after одного чата не задерживает остальные.
+// Горутина чата завершается, когда его очередь пустеет.
+type sender struct {
+	api    *tgbotapi.BotAPI
+	db     *database.DB
+	global *rate.Limiter
+
+	ctx    context.Context // отменяется, если очереди не успели опустеть при остановке
+	cancel context.CancelFunc
+	queues sync.WaitGroup
+
+	mu        sync.Mutex
+	pending   map[int64][]sendJob // очереди чатов с работающей горутиной
+	stopped   bool
+	chats     map[int64]*rate.Limiter
+	lastSweep time.Time
+	blocked   map[int64]bool
+}
+
+func newSender(api *tgbotapi.BotAPI, db *database.DB) *sender {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &sender{
+		api:     api,
+		db:      db,
+		global:  rate.NewLimiter(rate.Limit(globalSendRate), globalSendRate),
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: make(map[int64][]sendJob),
+		chats:   make(map[int64]*rate.Limiter),
+		blocked: make(map[int64]bool),
+	}
+
+	chats, err := db.GetBlockedChats()
+	if err != nil {
+		i18n.Logf("log.send.blocked_load_failed", err)
+	}
+	for _, chatID := range chats {
+		s.blocked[chatID] = true
+	}
+	return s
+}
+
+// Постановка запроса в очередь чата и ожидание результата. Запросы без
+// чата (ответы на callback и inline-запросы) выполняются сразу.
+func (s *sender) do(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chatID := chatIDOf(c)
+	if chatID != 0 && s.isBlocked(chatID) {
+		metrics.SendFailures.WithLabelValues("blocked").Inc()
+		return nil, errChatBlocked
+	}
+
+	if chatID == 0 {
+		s.mu.Lock()
+		stopped := s.stopped
+		s.mu.Unlock()
+		if stopped {
+			metrics.SendFailures.WithLabelValues("stopped").Inc()
+			return nil, errSenderStopped
+		}
+		return s.deliver(c)
+	}
+
+	job := sendJob{c: c, result: make(chan sendResult, 1)}
+	if err := s.enqueue(chatID, job); err != nil {
+		return nil, err
+	}
+	res := <-job.result
+	return res.resp, res.err
+}
+
+func (s *sender) enqueue(chatID int64, job sendJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		metrics.SendFailures.WithLabelValues("stopped").Inc()
+		return errSenderStopped
+	}
+	queue, running := s.pending[chatID]
+	if len(queue) >= chatQueueSize {
+		metrics.SendFailures.WithLabelValues("queue_full").Inc()
+		return errQueueFull
+	}
+	s.pending[chatID] = append(queue, job)
+	if !running {
+		s.queues.Add(1)
+		go s.drainChat(chatID)
+	}
+	return nil
+}
+
+// Горутина чата: отправка по порядку, пока очередь не опустеет
+func (s *sender) drainChat(chatID int64) {
+	defer s.queues.Done()
+	for {
+		s.mu.Lock()
+		queue := s.pending[chatID]
+		if len(queue) == 0 {
+			delete(s.pending, chatID)
+			s.mu.Unlock()
+			return
+		}
+		job := queue[0]
+		s.pending[chatID] = queue[1:]
+		s.mu.Unlock()
+
+		resp, err := s.deliver(job.c)
+		job.result <- sendResult{resp: resp, err: err}
+	}
+}
+
+// Остановка: новые запросы отклоняются, очереди дорабатываются не дольше
+// timeout, после чего ожидания лимитов и повторов прерываются
+func (s *sender) stop(timeout time.Duration) {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.queues.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		s.cancel()
+		<-drained
+	}
+	s.cancel()
+}
+
+func (s *sender) deliver(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chatID := chatIDOf(c)
+
+	var err error
+	for attempt := 1; attempt <= maxSendAttempts; attempt++ {
+		// Ответы на callback и inline-запросы не ограничиваются лимитами сообщений
+		if chatID != 0 {
+			if s.global.Wait(s.ctx) != nil || s.chatLimiter(chatID).Wait(s.ctx) != nil {
+				metrics.SendFailures.WithLabelValues("stopped").Inc()
+				return nil, errSenderStopped
+			}
+		}
+
+		var resp *tgbotapi.APIResponse
+		resp, err = s.api.Request(c)
+		if err == nil {
+			return resp, nil
+		}
+
+		delay := backoff(attempt)
+		var apiErr *tgbotapi.Error
+		if errors.As(err, &apiErr) {
+			switch {
+			case apiErr.RetryAfter > 0:
+				delay = time.Duration(apiErr.RetryAfter) * time.Second
+			case undeliverable(apiErr):
+				s.block(chatID, apiErr.Message)
+				metrics.SendFailures.WithLabelValues("forbidden").Inc()
+				return nil, err
+			case apiErr.Code < 500:
+				// Ошибка в самом запросе — повтор не поможет
+				metrics.SendFailures.WithLabelValues("rejected").Inc()
+				return nil, err
+			}
+		}
+
+		if attempt < maxSendAttempts {
+			i18n.Logf("log.send.retry", chatID, attempt, delay, err)
+			timer := time.NewTimer(delay)
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				metrics.SendFailures.WithLabelValues("stopped").Inc()
+				return nil, errSenderStopped
+			case <-timer.C:
+			}
+		}
+	}
+
+	i18n.Logf("log.send.failed", chatID, err)
+	metrics.SendFailures.WithLabelValues("retries_exhausted").Inc()
+	return nil, err
+}
+
+// Экспоненциальная задержка между повторами
+func backoff(attempt int) time.Duration {
+	delay := sendBackoffBase << (attempt - 1)
+	if delay > sendBackoffMax {
+		delay = sendBackoffMax
+	}
+	return delay
+}
+
+func (s *sender) chatLimiter(chatID int64) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.chats[chatID]
+	if !ok {
+		s.sweepLimiters(time.Now())
+		// Отрицательные ID — группы и каналы
+		if chatID < 0 {
+			l = rate.NewLimiter(rate.Every(groupChatInterval), 1)
+		} else {
+			l = rate.NewLimiter(rate.Every(time.Second), privateChatBurst)
+		}
+		s.chats[chatID] = l
+	}
+	return l
+}
+
+// Удаление лимитеров чатов, которые простаивают: лимит полностью
+// восстановился и очереди нет, поэтому новый лимитер ничем не отличается.
+// Вызывается под s.mu.
+func (s *sender) sweepLimiters(now time.Time) {
+	if now.Sub(s.lastSweep) < limiterSweepEvery {
+		return
+	}
+	s.lastSweep = now
+	for chatID, l := range s.chats {
+		if _, busy := s.pending[chatID]; !busy && l.TokensAt(now) >= float64(l.Burst()) {
+			delete(s.chats, chatID)
+		}
+	}
+}
+
+// Ошибка означает, что в чат больше нельзя писать
+func undeliverable(err *tgbotapi.Error) bool {
+	if err.Code != 403 && err.Code != 400 {
+		return false
+	}
+	message := strings.ToLower(err.Message)
+	for _, text := range undeliverableErrors {
+		if strings.Contains(message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sender) isBlocked(chatID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blocked[chatID]
+}
+
+func (s *sender) block(chatID int64, reason string) {
+	if chatID == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	already := s.blocked[chatID]
+	s.blocked[chatID] = true
+	s.mu.Unlock()
+	if already {
+		return
+	}
+
+	i18n.Logf("log.send.blocked", chatID, reason)
+	if err := s.db.BlockChat(chatID, reason); err != nil {
+		i18n.Logf("log.send.block_save_failed", chatID, err)
+	}
+}
+
+// Снятие отметки, когда пользователь снова пишет боту
+func (s *sender) unblock(chatID int64) {
+	s.mu.Lock()
+	wasBlocked := s.blocked[chatID]
+	delete(s.blocked, chatID)
+	s.mu.Unlock()
+	if !wasBlocked {
+		return
+	}
+
+	i18n.Logf("log.send.unblocked", chatID)
+	if err := s.db.UnblockChat(chatID); err != nil {
+		i18n.Logf("log.send.block_save_failed", chatID, err)
+	}
+}
+
+// Чат, в который адресован запрос; 0 для запросов без чата
+func chatIDOf(c tgbotapi.Chattable) int64 {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID
+	case tgbotapi.PhotoConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID
+	case tgbotapi.EditMessageMediaConfig:
+		return v.ChatID
+	case tgbotapi.DeleteMessageConfig:
+		return v.ChatID
+	}
+	return 0
+}
+
+// Отправка сообщения через очередь
+func (b *Bot) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var msg tgbotapi.Message
+	resp, err := b.outbox.do(c)
+	if err != nil {
+		return msg, err
+	}
+	err = json.Unmarshal(resp.Result, &msg)
+	return msg, err
+}
+
+// Запрос без сообщения в ответе (редактирование, удаление, ответы на callback)
+func (b *Bot) request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return b.outbox.do(c)
+}
+
+// Изменение статуса бота в чате: пользователь заблокировал или разблокировал бота
+func (b *Bot) handleMyChatMember(update *tgbotapi.ChatMemberUpdated) {
+	switch update.NewChatMember.Status {
+	case "kicked", "left":
+		b.outbox.block(update.Chat.ID, update.NewChatMember.Status)
+	case "member", "administrator":
+		b.outbox.unblock(update.Chat.ID)
+	}
+}
@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/time/rate"
+)
+
+func TestUndeliverable(t *testing.T) {
+	tests := []struct {
+		code    int
+		message string
+		want    bool
+	}{
+		{403, "Forbidden: bot was blocked by the user", true},
+		{403, "Forbidden: user is deactivated", true},
+		{403, "Forbidden: bot was kicked from the group chat", true},
+		{400, "Bad Request: chat not found", true},
+		{403, "Forbidden: not enough rights to send text messages to the chat", false},
+		{400, "Bad Request: message is too long", false},
+		{429, "Too Many Requests: retry after 5", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := undeliverable(&tgbotapi.Error{Code: tt.code, Message: tt.message}); got != tt.want {
+				t.Errorf("%v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepLimiters(t *testing.T) {
+	s := &sender{chats: make(map[int64]*rate.Limiter), pending: make(map[int64][]sendJob)}
+	now := time.Now()
+
+	idle := rate.NewLimiter(rate.Every(time.Second), privateChatBurst)
+	used := rate.NewLimiter(rate.Every(time.Hour), 1)
+	used.AllowN(now, 1)
+	queued := rate.NewLimiter(rate.Every(time.Second), privateChatBurst)
+	s.chats[1], s.chats[2], s.chats[3] = idle, used, queued
+	s.pending[3] = nil
+
+	s.sweepLimiters(now)
+	if _, ok := s.chats[1]; ok {
+		t.Error("лимитер простаивающего чата не удален")
+	}
+	if _, ok := s.chats[2]; !ok {
+		t.Error("удален лимитер, который еще не восстановился")
+	}
+	if _, ok := s.chats[3]; !ok {
+		t.Error("удален лимитер чата с очередью")
+	}
+}
@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"buff-youpin-checker/i18n"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram передает секрет вебхука в этом заголовке
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Максимальный размер тела апдейта, который принимаем
+const maxUpdateSize = 1 << 20
+
+// Запуск в режиме вебхука: регистрирует URL в Telegram и вешает обработчик
+// на mux по пути path. Апдейты попадают в тот же воркер-пул, что и при long polling.
+// HTTP-сервер для mux запускает вызывающая сторона; после его остановки
+// нужно вызвать Shutdown.
+func (b *Bot) StartWebhook(mux *http.ServeMux, webhookURL, path, secret string) error {
+	if webhookURL == "" {
+		return fmt.Errorf("webhook URL is not configured")
+	}
+	if secret == "" {
+		return fmt.Errorf("webhook secret is not configured")
+	}
+
+	params := tgbotapi.Params{}
+	params["url"] = webhookURL
+	params["secret_token"] = secret
+	if err := params.AddInterface("allowed_updates", []string{"message", "callback_query", "inline_query", "my_chat_member"}); err != nil {
+		return err
+	}
+
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("set webhook: %w", err)
+	}
+
+	b.startWorkers()
+	mux.Handle(path, b.webhookHandler(secret))
+
+	i18n.Logf("log.bot.webhook_registered", webhookURL)
+	return nil
+}
+
+func (b *Bot) webhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Пустой секрет не принимается: иначе подошел бы запрос без заголовка
+		token := r.Header.Get(webhookSecretHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxUpdateSize))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.Unmarshal(body, &update); err != nil {
+			i18n.Logf("log.bot.webhook_bad_update", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Если воркеры перегружены, ждем место в очереди; при обрыве соединения
+		// или остановке бота Telegram повторит доставку сам
+		if b.enqueue(r.Context(), update) {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandlerRejectsSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		header string
+	}{
+		{"нет заголовка", "s3cret", ""},
+		{"неверный секрет", "s3cret", "wrong"},
+		{"секрет не настроен", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bot{}
+			r := httptest.NewRequest(http.MethodPost, "/telegram/webhook",
+				strings.NewReader(`{"update_id":1,"message":{"text":"/setrole 1 admin","from":{"id":1}}}`))
+			if tt.header != "" {
+				r.Header.Set(webhookSecretHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			b.webhookHandler(tt.secret).ServeHTTP(w, r)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("код %d, ожидался %d", w.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
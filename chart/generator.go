@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
 	"github.com/wcharczuk/go-chart/v2"
 	"github.com/wcharczuk/go-chart/v2/drawing"
 )
@@ -18,14 +19,14 @@ func NewChartGenerator(db *database.DB) *ChartGenerator {
 	return &ChartGenerator{db: db}
 }
 
-func (cg *ChartGenerator) GeneratePriceChart(itemID int, days int) ([]byte, error) {
+func (cg *ChartGenerator) GeneratePriceChart(lang i18n.Lang, itemID int, days int) ([]byte, error) {
 	// Получаем историю цен
 	query := `SELECT price, recorded_at FROM price_history 
 			  WHERE item_id = $1 AND recorded_at >= $2 
 			  ORDER BY recorded_at ASC`
 	
 	startDate := time.Now().AddDate(0, 0, -days)
-	rows, err := cg.db.Query(query, itemID, startDate)
+	rows, err := cg.db.Query(query, itemID, startDate.UTC())
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +53,7 @@ func (cg *ChartGenerator) GeneratePriceChart(itemID int, days int) ([]byte, erro
 
 	// Создаем график
 	graph := chart.Chart{
-		Title: "Динамика цены",
+		Title: i18n.T(lang, "chart.price.title"),
 		TitleStyle: chart.Style{
 			FontSize: 16,
 		},
@@ -67,17 +68,17 @@ func (cg *ChartGenerator) GeneratePriceChart(itemID int, days int) ([]byte, erro
 			},
 		},
 		XAxis: chart.XAxis{
-			Name: "Дата",
+			Name: i18n.T(lang, "chart.axis.date"),
 			Style: chart.Style{
 				TextRotationDegrees: 45.0,
 			},
 		},
 		YAxis: chart.YAxis{
-			Name: "Цена (₽)",
+			Name: i18n.T(lang, "chart.axis.price"),
 		},
 		Series: []chart.Series{
 			chart.TimeSeries{
-				Name: "Цена",
+				Name: i18n.T(lang, "chart.price.series"),
 				Style: chart.Style{
 					StrokeColor: drawing.ColorBlue,
 					StrokeWidth: 2,
@@ -90,7 +91,7 @@ func (cg *ChartGenerator) GeneratePriceChart(itemID int, days int) ([]byte, erro
 
 	// Добавляем линию тренда если данных достаточно
 	if len(prices) > 5 {
-		trendSeries := cg.calculateTrendLine(timestamps, prices)
+		trendSeries := cg.calculateTrendLine(lang, timestamps, prices)
 		graph.Series = append(graph.Series, trendSeries)
 	}
 
@@ -108,7 +109,7 @@ func (cg *ChartGenerator) GeneratePriceChart(itemID int, days int) ([]byte, erro
 	return buffer.Bytes(), nil
 }
 
-func (cg *ChartGenerator) calculateTrendLine(timestamps []time.Time, prices []float64) chart.TimeSeries {
+func (cg *ChartGenerator) calculateTrendLine(lang i18n.Lang, timestamps []time.Time, prices []float64) chart.TimeSeries {
 	if len(prices) < 2 {
 		return chart.TimeSeries{}
 	}
@@ -137,7 +138,7 @@ func (cg *ChartGenerator) calculateTrendLine(timestamps []time.Time, prices []fl
 	}
 
 	return chart.TimeSeries{
-		Name: "Тренд",
+		Name: i18n.T(lang, "chart.price.trend"),
 		Style: chart.Style{
 			StrokeColor:     drawing.ColorRed,
 			StrokeWidth:     2,
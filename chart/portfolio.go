@@ -0,0 +1,71 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/portfolio"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// График стоимости портфеля по дням
+func (cg *ChartGenerator) GeneratePortfolioChart(lang i18n.Lang, points []portfolio.ValuePoint) ([]byte, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("not enough portfolio history")
+	}
+
+	var timestamps []time.Time
+	var values []float64
+	for _, p := range points {
+		timestamps = append(timestamps, p.Time)
+		values = append(values, p.Value)
+	}
+
+	graph := chart.Chart{
+		Title: i18n.T(lang, "chart.portfolio.title"),
+		TitleStyle: chart.Style{
+			FontSize: 16,
+		},
+		Width:  800,
+		Height: 400,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    20,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+		},
+		XAxis: chart.XAxis{
+			Name: i18n.T(lang, "chart.axis.date"),
+			Style: chart.Style{
+				TextRotationDegrees: 45.0,
+			},
+		},
+		YAxis: chart.YAxis{
+			Name: i18n.T(lang, "chart.axis.value"),
+		},
+		Series: []chart.Series{
+			chart.TimeSeries{
+				Name: i18n.T(lang, "chart.portfolio.series"),
+				Style: chart.Style{
+					StrokeColor: drawing.ColorBlue,
+					FillColor:   drawing.ColorBlue.WithAlpha(48),
+					StrokeWidth: 2,
+				},
+				XValues: timestamps,
+				YValues: values,
+			},
+		},
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	if err := graph.Render(chart.PNG, buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
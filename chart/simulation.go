@@ -0,0 +1,105 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/optimizer"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// Веерная диаграмма стоимости портфеля по дням горизонта: коридор
+// 5–95-го перцентилей, медиана и вложенная сумма
+func (cg *ChartGenerator) GenerateSimulationChart(lang i18n.Lang, sim *optimizer.Simulation) ([]byte, error) {
+	if sim == nil || len(sim.P50) < 2 {
+		return nil, fmt.Errorf("no simulation")
+	}
+
+	days := make([]float64, len(sim.P50))
+	cost := make([]float64, len(sim.P50))
+	for d := range days {
+		days[d] = float64(d)
+		cost[d] = sim.Cost
+	}
+
+	graph := chart.Chart{
+		Title: i18n.T(lang, "chart.simulation.title"),
+		TitleStyle: chart.Style{
+			FontSize: 16,
+		},
+		Width:  800,
+		Height: 400,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:    20,
+				Left:   20,
+				Right:  20,
+				Bottom: 20,
+			},
+		},
+		XAxis: chart.XAxis{
+			Name: i18n.T(lang, "chart.axis.days"),
+			ValueFormatter: func(v interface{}) string {
+				return fmt.Sprintf("%.0f", v.(float64))
+			},
+		},
+		YAxis: chart.YAxis{
+			Name: i18n.T(lang, "chart.axis.value"),
+		},
+		// Коридор рисуется заливкой 95-го перцентиля, поверх которой
+		// 5-й перцентиль заливается цветом фона
+		Series: []chart.Series{
+			chart.ContinuousSeries{
+				Name: i18n.T(lang, "chart.simulation.p95"),
+				Style: chart.Style{
+					StrokeColor: drawing.ColorBlue.WithAlpha(96),
+					FillColor:   drawing.ColorBlue.WithAlpha(48),
+					StrokeWidth: 1,
+				},
+				XValues: days,
+				YValues: sim.P95,
+			},
+			chart.ContinuousSeries{
+				Name: i18n.T(lang, "chart.simulation.p5"),
+				Style: chart.Style{
+					StrokeColor: drawing.ColorBlue.WithAlpha(96),
+					FillColor:   drawing.ColorWhite,
+					StrokeWidth: 1,
+				},
+				XValues: days,
+				YValues: sim.P5,
+			},
+			chart.ContinuousSeries{
+				Name: i18n.T(lang, "chart.simulation.median"),
+				Style: chart.Style{
+					StrokeColor: drawing.ColorBlue,
+					StrokeWidth: 2,
+				},
+				XValues: days,
+				YValues: sim.P50,
+			},
+			chart.ContinuousSeries{
+				Name: i18n.T(lang, "chart.simulation.cost"),
+				Style: chart.Style{
+					StrokeColor:     drawing.ColorRed,
+					StrokeWidth:     1,
+					StrokeDashArray: []float64{5, 5},
+				},
+				XValues: days,
+				YValues: cost,
+			},
+		},
+	}
+	graph.Elements = []chart.Renderable{
+		chart.Legend(&graph),
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	if err := graph.Render(chart.PNG, buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
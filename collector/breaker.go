@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Состояние автомата защиты источника
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // запросы идут как обычно
+	BreakerOpen                         // источник отключен до конца паузы
+	BreakerHalfOpen                     // пробный запрос после паузы
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// Источник временно отключен автоматом защиты
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Автомат защиты: после threshold неудачных сборов подряд (или сразу при
+// ошибке ключа) источник отключается на cooldown, затем делается одна
+// пробная попытка. Успех закрывает автомат, неудача снова открывает его.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Можно ли обращаться к источнику; при открытом автомате возвращает
+// время следующей попытки
+func (b *breaker) allow(now time.Time) (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.cooldown)
+		if now.Before(retryAt) {
+			return retryAt, false
+		}
+		b.state = BreakerHalfOpen
+	}
+	return time.Time{}, true
+}
+
+// Успешный сбор; recovered == true, если автомат был открыт
+func (b *breaker) success() (recovered bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recovered = b.state != BreakerClosed
+	b.state = BreakerClosed
+	b.failures = 0
+	return recovered
+}
+
+// Неудачный сбор; opened == true, если автомат только что открылся.
+// fatal открывает автомат сразу, без набора порога.
+func (b *breaker) failure(now time.Time, fatal bool) (opened bool, failures int, retryAt time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	wasOpen := b.state == BreakerHalfOpen
+	if fatal || wasOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = now
+	}
+
+	// Повторное открытие после пробной попытки не считается новым событием
+	opened = b.state == BreakerOpen && !wasOpen
+	return opened, b.failures, b.openedAt.Add(b.cooldown)
+}
+
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,331 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"buff-youpin-checker/config"
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/market"
+	"buff-youpin-checker/metrics"
+)
+
+// Сборщик цен с market.csgo.com
+type Collector struct {
+	client  *market.Client
+	db      *database.DB
+	cfg     config.MarketConfig
+	breaker *breaker // автомат защиты источника client.Source()
+
+	// Последние записанные цены для режима StoreChangesOnly. Кэш
+	// перечитывается, если последний снимок в базе записан не нами
+	// (например, другим экземпляром, пока этот не был лидером).
+	lastPrices   map[int]database.LastPrice
+	lastSnapshot int
+
+	alertMu sync.Mutex
+	alert   func(Alert)
+}
+
+// Событие для администраторов: источник отключен из-за постоянных
+// ошибок или снова работает
+type Alert struct {
+	Source   string
+	Kind     market.ErrorKind
+	Message  string    // текст ошибки без секретов (market.Redact)
+	Failures int       // неудачных сборов подряд
+	RetryAt  time.Time // когда будет следующая попытка
+	Resolved bool      // источник восстановился
+}
+
+// Итог одного прохода сбора
+type Result struct {
+	Received  int // предметов в ответе API
+	Stored    int // записано цен
+	Unchanged int // цена и объем не изменились, строка не записана
+	Failed    int // предметов с ошибкой записи
+}
+
+// cfg задает повторы запросов, параметры автомата защиты и режим записи цен
+func New(client *market.Client, db *database.DB, cfg config.MarketConfig) *Collector {
+	return &Collector{
+		client:  client,
+		db:      db,
+		cfg:     cfg,
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// Получатель оповещений о состоянии источника
+func (c *Collector) OnAlert(fn func(Alert)) {
+	c.alertMu.Lock()
+	defer c.alertMu.Unlock()
+	c.alert = fn
+}
+
+func (c *Collector) notify(alert Alert) {
+	c.alertMu.Lock()
+	fn := c.alert
+	c.alertMu.Unlock()
+	if fn != nil {
+		fn(alert)
+	}
+}
+
+// Один проход сбора: получение текущих цен и запись в историю.
+// Каждый запуск фиксируется в collection_runs для /stats.
+// progress (может быть nil) получает число обработанных предметов.
+// При отмене ctx текущая запись дописывается, остальные предметы пропускаются.
+// Пока автомат защиты открыт, источник не опрашивается и Run сразу
+// возвращает ErrCircuitOpen. Текст ошибки очищен от секретов (market.Redact).
+func (c *Collector) Run(ctx context.Context, progress func(done, total int)) (*Result, error) {
+	started := time.Now()
+
+	var result *Result
+	var err error
+	if retryAt, ok := c.breaker.allow(started); ok {
+		result, err = c.collect(ctx, progress)
+		c.trackHealth(err)
+	} else {
+		i18n.Logf("log.collect.circuit_open", c.client.Source(), retryAt.Format("15:04:05"))
+		err = ErrCircuitOpen
+	}
+	if result == nil {
+		result = &Result{}
+	}
+
+	finished := time.Now()
+	if recErr := c.db.RecordCollectionRun(started, finished, result.Received, result.Stored, result.Failed, market.Redact(err)); recErr != nil {
+		i18n.Logf("log.collect.record_failed", recErr)
+	}
+
+	metrics.CollectionDuration.Observe(finished.Sub(started).Seconds())
+	metrics.ItemsFetched.Add(float64(result.Received))
+	metrics.ItemsStored.Add(float64(result.Stored))
+	metrics.ItemsUnchanged.Add(float64(result.Unchanged))
+	metrics.ItemsFailed.Add(float64(result.Failed))
+	if err == nil {
+		metrics.LastSuccessfulCollection.Set(float64(finished.Unix()))
+	}
+
+	return result, market.Redacted(err)
+}
+
+func (c *Collector) collect(ctx context.Context, progress func(done, total int)) (*Result, error) {
+	i18n.Logf("log.collect.started")
+
+	// Получаем текущие цены
+	priceResponse, err := c.fetchPrices(ctx)
+	if err != nil {
+		i18n.Logf("log.collect.fetch_failed", market.Redact(err))
+		return nil, err
+	}
+	// Время снимка с точностью до микросекунд, как в TIMESTAMP
+	fetchedAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	i18n.Logf("log.collect.received", len(priceResponse.Items))
+
+	changesOnly := c.cfg.StoreChangesOnly
+	if changesOnly {
+		if err := c.loadLastPrices(); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshot := &database.PriceSnapshot{
+		Source:    c.client.Source(),
+		FetchedAt: fetchedAt,
+		Currency:  c.client.Currency(),
+		ItemCount: len(priceResponse.Items),
+	}
+	if priceResponse.Time > 0 {
+		snapshot.APITime = time.Unix(priceResponse.Time, 0)
+	}
+	if changesOnly {
+		snapshot.Heartbeat = c.cfg.Heartbeat
+	}
+	if err := c.db.CreateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	c.lastSnapshot = snapshot.ID
+
+	result := &Result{Received: len(priceResponse.Items)}
+	seen := make([]int, 0, len(priceResponse.Items))
+
+	// Итоги снимка записываются и при прерванном сборе
+	defer func() {
+		if err := c.db.MarkItemsSeen(snapshot, seen); err != nil {
+			i18n.Logf("log.collect.snapshot_failed", err)
+		}
+		if err := c.db.FinishSnapshot(snapshot.ID, result.Stored); err != nil {
+			i18n.Logf("log.collect.snapshot_failed", err)
+		}
+	}()
+
+	for i, item := range priceResponse.Items {
+		if err := ctx.Err(); err != nil {
+			i18n.Logf("log.collect.interrupted", result.Stored)
+			return result, err
+		}
+
+		if progress != nil {
+			progress(i, len(priceResponse.Items))
+		}
+
+		// Цены хранятся с точностью до копейки
+		price := math.Round(parseFloat(item.Price)*100) / 100
+		if price <= 0 {
+			continue
+		}
+		volume, _ := strconv.Atoi(item.Volume)
+
+		// Создаем или обновляем предмет
+		dbItem := &database.Item{
+			HashName:   item.MarketHashName,
+			MarketName: item.MarketHashName,
+			ClassID:    "unknown",
+			InstanceID: "0",
+		}
+
+		if err := c.db.CreateItem(dbItem); err != nil {
+			i18n.Logf("log.collect.item_failed", item.MarketHashName, err)
+			result.Failed++
+			continue
+		}
+		seen = append(seen, dbItem.ID)
+
+		if changesOnly {
+			last, ok := c.lastPrices[dbItem.ID]
+			if ok && last.Price == price && last.Volume == volume && fetchedAt.Sub(last.RecordedAt) < c.cfg.Heartbeat {
+				result.Unchanged++
+				continue
+			}
+		}
+
+		// Добавляем цену в историю
+		if err := c.db.AddSnapshotPrice(snapshot, dbItem.ID, price, volume); err != nil {
+			i18n.Logf("log.collect.price_failed", item.MarketHashName, err)
+			result.Failed++
+			continue
+		}
+		if changesOnly {
+			c.lastPrices[dbItem.ID] = database.LastPrice{Price: price, Volume: volume, RecordedAt: fetchedAt}
+		}
+
+		result.Stored++
+	}
+
+	if changesOnly {
+		i18n.Logf("log.collect.done_changes", result.Stored, result.Unchanged)
+	} else {
+		i18n.Logf("log.collect.done", result.Stored)
+	}
+	return result, nil
+}
+
+// Загрузка последних цен из базы, если кэш пуст или устарел
+func (c *Collector) loadLastPrices() error {
+	lastID, err := c.db.GetLastSnapshotID(c.client.Source())
+	if err != nil {
+		return err
+	}
+	if c.lastPrices != nil && lastID == c.lastSnapshot {
+		return nil
+	}
+
+	prices, err := c.db.GetLastPrices(c.client.Source())
+	if err != nil {
+		return err
+	}
+	c.lastPrices = prices
+	i18n.Logf("log.collect.cache_loaded", len(prices))
+	return nil
+}
+
+// Запрос цен с повторами временных ошибок (сеть, 429, 5xx)
+func (c *Collector) fetchPrices(ctx context.Context) (*market.PriceResponse, error) {
+	for attempt := 1; ; attempt++ {
+		response, err := c.client.GetPrices(ctx)
+		if err == nil {
+			return response, nil
+		}
+		if !market.IsTemporary(err) || attempt >= c.cfg.RetryAttempts {
+			return nil, err
+		}
+
+		delay := c.backoff(attempt, err)
+		i18n.Logf("log.collect.retry", attempt, delay.Round(time.Millisecond), market.Redact(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Экспоненциальная задержка со случайной составляющей, чтобы реплики
+// не повторяли запросы одновременно. Retry-After от API имеет приоритет.
+func (c *Collector) backoff(attempt int, err error) time.Duration {
+	delay := c.cfg.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > c.cfg.RetryMaxDelay {
+		delay = c.cfg.RetryMaxDelay
+	}
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	var apiErr *market.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > delay {
+		delay = apiErr.RetryAfter
+	}
+	return delay
+}
+
+// Учет результата сбора в автомате защиты и оповещения администраторов.
+// Сбоем источника считаются только ошибки market API: недоступная база
+// не должна отключать источник и сообщать, что не работает рынок.
+func (c *Collector) trackHealth(err error) {
+	source := c.client.Source()
+
+	var apiErr *market.APIError
+	switch {
+	case err == nil:
+		if c.breaker.success() {
+			i18n.Logf("log.collect.recovered", source)
+			c.notify(Alert{Source: source, Resolved: true})
+		}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// Остановка приложения — не сбой источника
+	case !errors.As(err, &apiErr):
+		i18n.Logf("log.collect.storage_failed", market.Redact(err))
+	default:
+		kind := market.KindOf(err)
+		opened, failures, retryAt := c.breaker.failure(time.Now(), kind == market.ErrAuth)
+		if opened {
+			i18n.Logf("log.collect.circuit_opened", source, failures, market.Redact(err))
+			c.notify(Alert{Source: source, Kind: kind, Message: market.Redact(err), Failures: failures, RetryAt: retryAt})
+		}
+	}
+	metrics.BreakerState.WithLabelValues(source).Set(float64(c.breaker.State()))
+}
+
+// Состояние автомата защиты источника
+func (c *Collector) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// Парсинг строки в float64
+func parseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
@@ -1,44 +1,583 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"buff-youpin-checker/cron"
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+// Файл конфигурации по умолчанию; путь можно переопределить в CONFIG_FILE
+const defaultConfigFile = "config.yaml"
+
+// Заменитель секретов в String()
+const redacted = "[redacted]"
+
 type Config struct {
-	TelegramToken string
-	MarketAPIKey  string
-	DBHost        string
-	DBPort        string
-	DBUser        string
-	DBPassword    string
-	DBName        string
-	Port          string
+	Telegram TelegramConfig `yaml:"telegram"`
+	Market   MarketConfig   `yaml:"market"`
+	Database DatabaseConfig `yaml:"database"`
+	Server   ServerConfig   `yaml:"server"`
+	Locale   LocaleConfig   `yaml:"locale"`
+	Access   AccessConfig   `yaml:"access"`
+	Schedule ScheduleConfig `yaml:"schedule"`
+	Analysis AnalysisConfig `yaml:"analysis"`
+	Budget   BudgetConfig   `yaml:"budget"`
+}
+
+type TelegramConfig struct {
+	Token         string `yaml:"token"`
+	Mode          string `yaml:"mode"`           // polling или webhook
+	WebhookURL    string `yaml:"webhook_url"`    // публичный URL вебхука
+	WebhookPath   string `yaml:"webhook_path"`   // путь обработчика вебхука на нашем сервере
+	WebhookSecret string `yaml:"webhook_secret"` // секрет для заголовка X-Telegram-Bot-Api-Secret-Token
+}
+
+// Источник цен
+type MarketConfig struct {
+	APIKey    string        `yaml:"api_key"`
+	BaseURL   string        `yaml:"base_url"`
+	Source    string        `yaml:"source"`     // имя источника в истории цен
+	Currency  string        `yaml:"currency"`   // валюта прайс-листа
+	RateLimit float64       `yaml:"rate_limit"` // запросов в секунду
+	Timeout   time.Duration `yaml:"timeout"`
+
+	RetryAttempts    int           `yaml:"retry_attempts"`   // попыток на временные ошибки (сеть, 429, 5xx)
+	RetryBaseDelay   time.Duration `yaml:"retry_base_delay"` // первая задержка, дальше удваивается
+	RetryMaxDelay    time.Duration `yaml:"retry_max_delay"`
+	BreakerThreshold int           `yaml:"breaker_threshold"` // неудачных сборов подряд до отключения источника
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`  // пауза перед пробной попыткой
+
+	// Записывать цену, только если изменились цена или объем; раз в
+	// heartbeat строка пишется в любом случае
+	StoreChangesOnly bool          `yaml:"store_changes_only"`
+	Heartbeat        time.Duration `yaml:"heartbeat"`
+}
+
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+}
+
+type ServerConfig struct {
+	Port            string        `yaml:"port"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	MaxDataAge      time.Duration `yaml:"max_data_age"` // /marketz: допустимый возраст последнего успешного сбора
+	APIToken        string        `yaml:"api_token"`    // токен JSON API; пустой — API без авторизации
+}
+
+type LocaleConfig struct {
+	Language    string `yaml:"language"`     // язык бота по умолчанию (ru/en)
+	LogLanguage string `yaml:"log_language"` // язык журналов (ru/en)
+}
+
+type AccessConfig struct {
+	Mode   string  `yaml:"mode"`   // open или allowlist
+	Admins []int64 `yaml:"admins"` // Telegram ID администраторов
+}
+
+// Расписание фоновых задач
+type ScheduleConfig struct {
+	Timezone  string                 `yaml:"timezone"`  // часовой пояс cron-выражений
+	Retention time.Duration          `yaml:"retention"` // срок хранения журналов запусков
+	Jobs      map[string]JobSchedule `yaml:"jobs"`      // по имени задачи
+
+	// Выбор лидера между экземплярами: задачи выполняет только один из них.
+	// Отключается, если соединения идут через пулер в режиме транзакций.
+	LeaderElection      bool          `yaml:"leader_election"`
+	LeaderCheckInterval time.Duration `yaml:"leader_check_interval"` // проверка и перехват блокировки
+}
+
+// Политика для запусков, пропущенных, пока приложение не работало
+const (
+	CatchUpRunOnce = "run_once" // выполнить один раз сразу после старта
+	CatchUpSkip    = "skip"     // дождаться следующего времени по расписанию
+)
+
+type JobSchedule struct {
+	Cron     string `yaml:"cron"`
+	CatchUp  string `yaml:"catch_up"` // run_once (по умолчанию) или skip
+	Disabled bool   `yaml:"disabled"`
+}
+
+// Параметры оценки трендов
+type AnalysisConfig struct {
+	HistoryDays  int     `yaml:"history_days"`   // окно истории цен для анализа
+	BuyMinScore  int     `yaml:"buy_min_score"`  // BUY: минимальный рейтинг
+	BuyMinGrowth float64 `yaml:"buy_min_growth"` // BUY: минимальный рост, %
+	HoldMinScore int     `yaml:"hold_min_score"` // HOLD: минимальный рейтинг, ниже — SELL
+
+	TopMinScore     int `yaml:"top_min_score"`     // минимальный рейтинг в подборках /top и бюджета
+	StickerMinScore int `yaml:"sticker_min_score"` // то же для стикеров, чтобы выборка не была пустой
+
+	// Метрики риска по дневным лог-доходностям
+	RiskWindows    []int   `yaml:"risk_windows"`    // окна в днях, не больше history_days
+	RiskConfidence float64 `yaml:"risk_confidence"` // уровень доверия VaR/CVaR
+	RiskFreeRate   float64 `yaml:"risk_free_rate"`  // безрисковая ставка для Sharpe/Sortino, % годовых
+}
+
+// Параметры калькулятора бюджета
+type BudgetConfig struct {
+	MinROI      float64            `yaml:"min_roi"`      // минимальный чистый ROI позиции за horizon_days после издержек (множитель)
+	MaxPerItem  int                `yaml:"max_per_item"` // не больше штук одного предмета
+	MaxItems    int                `yaml:"max_items"`    // не больше позиций в рекомендации
+	Allocations map[string]float64 `yaml:"allocations"`  // наибольшая доля бюджета по категориям
+
+	HorizonDays    int          `yaml:"horizon_days"`    // срок владения для оценки доходности и риска
+	LiquidityShare float64      `yaml:"liquidity_share"` // не больше этой доли объема предмета
+	RiskAversion   RiskAversion `yaml:"risk_aversion"`   // штраф за риск по уровням, которые выбирает пользователь
+	Simulations    int          `yaml:"simulations"`     // траекторий Монте-Карло для распределения результата
+
+	// Издержки продажи: комиссии площадки, на которой будут продаваться
+	// предметы, и проскальзывание impact·√(штук/объем), не больше max_slippage.
+	// Без данных об объеме проскальзывание равно max_slippage.
+	Venue          string               `yaml:"venue"`
+	Fees           map[string]VenueFees `yaml:"fees"`
+	SlippageImpact float64              `yaml:"slippage_impact"`
+	MaxSlippage    float64              `yaml:"max_slippage"`
+}
+
+// Комиссии площадки, доли от суммы
+type VenueFees struct {
+	Sale     float64 `yaml:"sale"`     // с продажи
+	Withdraw float64 `yaml:"withdraw"` // за вывод денег
 }
 
-func Load() *Config {
+// Коэффициент неприятия риска λ для уровней риска
+type RiskAversion struct {
+	Low    float64 `yaml:"low"`
+	Medium float64 `yaml:"medium"`
+	High   float64 `yaml:"high"`
+}
+
+// Уровни риска калькулятора бюджета
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// λ для уровня риска; неизвестный уровень считается средним
+func (r RiskAversion) For(level string) float64 {
+	switch level {
+	case RiskLow:
+		return r.Low
+	case RiskHigh:
+		return r.High
+	default:
+		return r.Medium
+	}
+}
+
+// Значения по умолчанию
+func Default() *Config {
+	return &Config{
+		Telegram: TelegramConfig{
+			Mode:        "polling",
+			WebhookPath: "/telegram/webhook",
+		},
+		Market: MarketConfig{
+			BaseURL:   "https://market.csgo.com/api/v2",
+			Source:    "market.csgo.com",
+			Currency:  "RUB",
+			RateLimit: 4, // меньше 5 для безопасности
+			Timeout:   30 * time.Second,
+
+			RetryAttempts:    4,
+			RetryBaseDelay:   2 * time.Second,
+			RetryMaxDelay:    time.Minute,
+			BreakerThreshold: 3,
+			BreakerCooldown:  15 * time.Minute,
+			Heartbeat:        6 * time.Hour,
+		},
+		Database: DatabaseConfig{
+			Host:    "localhost",
+			Port:    "5432",
+			User:    "postgres",
+			Name:    "skin_analyzer",
+			SSLMode: "disable",
+		},
+		Server: ServerConfig{
+			Port:            "8080",
+			ShutdownTimeout: 30 * time.Second,
+			MaxDataAge:      30 * time.Minute,
+		},
+		Locale: LocaleConfig{Language: "ru", LogLanguage: "ru"},
+		Access: AccessConfig{Mode: "open"},
+		Schedule: ScheduleConfig{
+			Timezone:            "UTC",
+			Retention:           90 * 24 * time.Hour,
+			LeaderElection:      true,
+			LeaderCheckInterval: 15 * time.Second,
+			Jobs: map[string]JobSchedule{
+				"collection": {Cron: "*/10 * * * *", CatchUp: CatchUpRunOnce},
+				"analysis":   {Cron: "*/30 * * * *", CatchUp: CatchUpRunOnce},
+				"candles":    {Cron: "5 * * * *", CatchUp: CatchUpRunOnce},
+				"prune":      {Cron: "30 3 * * *", CatchUp: CatchUpSkip},
+				"digests":    {Cron: "* * * * *", CatchUp: CatchUpRunOnce},
+			},
+		},
+		Analysis: AnalysisConfig{
+			HistoryDays:  30,
+			BuyMinScore:  8,
+			BuyMinGrowth: 5,
+			HoldMinScore: 6,
+
+			TopMinScore:     6,
+			StickerMinScore: 4,
+
+			RiskWindows:    []int{7, 30},
+			RiskConfidence: 0.95,
+		},
+		Budget: BudgetConfig{
+			MinROI:     1.02,
+			MaxPerItem: 20,
+			MaxItems:   8,
+			Allocations: map[string]float64{
+				"knives":     0.4,  // стабильно
+				"weapons":    0.3,  // ликвидно
+				"containers": 0.15, // долгосрочно
+				"gloves":     0.1,  // премиум
+				"stickers":   0.05, // высокий риск
+			},
+
+			HorizonDays:    30,
+			LiquidityShare: 0.1,
+			RiskAversion:   RiskAversion{Low: 10, Medium: 4, High: 1},
+			Simulations:    2000,
+
+			Venue: "market.csgo.com",
+			Fees: map[string]VenueFees{
+				"market.csgo.com": {Sale: 0.05, Withdraw: 0.05},
+				"buff163":         {Sale: 0.025, Withdraw: 0.01},
+				"youpin":          {Sale: 0.01, Withdraw: 0.01},
+			},
+			SlippageImpact: 0.05,
+			MaxSlippage:    0.15,
+		},
+	}
+}
+
+// Загрузка конфигурации: значения по умолчанию, затем YAML-файл
+// (CONFIG_FILE или config.yaml, если есть), затем переменные окружения.
+// Возвращает ошибку, если файл не читается или значения некорректны.
+func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
 
-	// Функция для получения значения с fallback
-	getEnvWithDefault := func(key, defaultValue string) string {
-		if value := os.Getenv(key); value != "" {
-			return value
+	cfg := Default()
+
+	path := os.Getenv("CONFIG_FILE")
+	if err := cfg.loadFile(path); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Чтение YAML-файла поверх текущих значений. Без явного пути
+// отсутствие config.yaml не ошибка.
+func (c *Config) loadFile(path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	// Категории бюджета в файле заменяют умолчания целиком, а не дополняют их
+	allocations := c.Budget.Allocations
+	c.Budget.Allocations = nil
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	if c.Budget.Allocations == nil {
+		c.Budget.Allocations = allocations
+	}
+	return nil
+}
+
+// Переменные окружения переопределяют значения из файла
+func (c *Config) applyEnv() error {
+	var errs []error
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	float := func(key string, dst *float64) {
+		if v := os.Getenv(key); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = f
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v := os.Getenv(key); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = b
 		}
-		return defaultValue
 	}
 
-	return &Config{
-		TelegramToken: getEnvWithDefault("TELEGRAM_BOT_TOKEN", ""),
-		MarketAPIKey:  getEnvWithDefault("MARKET_API_KEY", ""),
-		DBHost:        getEnvWithDefault("DB_HOST", "localhost"),
-		DBPort:        getEnvWithDefault("DB_PORT", "5432"),
-		DBUser:        getEnvWithDefault("DB_USER", "postgres"),
-		DBPassword:    getEnvWithDefault("DB_PASSWORD", ""),
-		DBName:        getEnvWithDefault("DB_NAME", "skin_analyzer"),
-		Port:          getEnvWithDefault("PORT", "8080"),
-	}
-}
\ No newline at end of file
+	str("TELEGRAM_BOT_TOKEN", &c.Telegram.Token)
+	str("BOT_MODE", &c.Telegram.Mode)
+	str("WEBHOOK_URL", &c.Telegram.WebhookURL)
+	str("WEBHOOK_PATH", &c.Telegram.WebhookPath)
+	str("WEBHOOK_SECRET", &c.Telegram.WebhookSecret)
+
+	str("MARKET_API_KEY", &c.Market.APIKey)
+	str("MARKET_BASE_URL", &c.Market.BaseURL)
+	str("MARKET_CURRENCY", &c.Market.Currency)
+	float("MARKET_RATE_LIMIT", &c.Market.RateLimit)
+	boolean("STORE_CHANGES_ONLY", &c.Market.StoreChangesOnly)
+
+	str("DB_HOST", &c.Database.Host)
+	str("DB_PORT", &c.Database.Port)
+	str("DB_USER", &c.Database.User)
+	str("DB_PASSWORD", &c.Database.Password)
+	str("DB_NAME", &c.Database.Name)
+	str("DB_SSLMODE", &c.Database.SSLMode)
+
+	str("PORT", &c.Server.Port)
+	str("API_TOKEN", &c.Server.APIToken)
+	str("DEFAULT_LANGUAGE", &c.Locale.Language)
+	str("LOG_LANGUAGE", &c.Locale.LogLanguage)
+
+	str("ACCESS_MODE", &c.Access.Mode)
+	if v := os.Getenv("ADMIN_IDS"); v != "" {
+		ids, err := parseIDs(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ADMIN_IDS: %w", err))
+		}
+		c.Access.Admins = ids
+	}
+
+	jobCron := func(key, job string) {
+		if v := os.Getenv(key); v != "" {
+			js := c.Schedule.Jobs[job]
+			js.Cron = v
+			c.Schedule.Jobs[job] = js
+		}
+	}
+	jobCron("COLLECTION_SCHEDULE", "collection")
+	jobCron("ANALYSIS_SCHEDULE", "analysis")
+
+	return errors.Join(errs...)
+}
+
+// Допустимый секрет вебхука по правилам Telegram
+var webhookSecret = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Проверка значений; возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Telegram.Token != "", "telegram.token (TELEGRAM_BOT_TOKEN) is required")
+	check(c.Telegram.Mode == "polling" || c.Telegram.Mode == "webhook",
+		"telegram.mode must be polling or webhook, got %q", c.Telegram.Mode)
+	if c.Telegram.Mode == "webhook" {
+		u, err := url.Parse(c.Telegram.WebhookURL)
+		check(err == nil && u.Scheme == "https" && u.Host != "",
+			"telegram.webhook_url must be an https URL in webhook mode")
+		check(strings.HasPrefix(c.Telegram.WebhookPath, "/"), "telegram.webhook_path must start with /")
+		// Без секрета любой может прислать апдейт от имени администратора
+		check(webhookSecret.MatchString(c.Telegram.WebhookSecret),
+			"telegram.webhook_secret (WEBHOOK_SECRET) is required in webhook mode: 1-256 characters A-Z, a-z, 0-9, _ and -")
+	}
+
+	check(c.Market.APIKey != "", "market.api_key (MARKET_API_KEY) is required")
+	check(c.Market.BaseURL != "", "market.base_url is required")
+	check(c.Market.Source != "", "market.source is required")
+	check(c.Market.Currency != "", "market.currency is required")
+	// Выше 5 запросов в секунду market.csgo.com блокирует ключ
+	check(c.Market.RateLimit > 0 && c.Market.RateLimit <= 5,
+		"market.rate_limit must be in (0, 5], got %v", c.Market.RateLimit)
+	check(c.Market.Timeout > 0, "market.timeout must be positive")
+	check(c.Market.RetryAttempts >= 1, "market.retry_attempts must be at least 1")
+	check(c.Market.RetryBaseDelay > 0 && c.Market.RetryBaseDelay <= c.Market.RetryMaxDelay,
+		"market.retry_base_delay must be positive and not above retry_max_delay")
+	check(c.Market.BreakerThreshold >= 1, "market.breaker_threshold must be at least 1")
+	check(c.Market.BreakerCooldown > 0, "market.breaker_cooldown must be positive")
+	// Не реже раза в сутки: дневные цены и свечи строятся по записанным строкам
+	check(c.Market.Heartbeat > 0 && c.Market.Heartbeat <= 24*time.Hour,
+		"market.heartbeat must be between 0 and 24h")
+
+	check(c.Database.Host != "", "database.host is required")
+	check(validPort(c.Database.Port), "database.port must be a port number, got %q", c.Database.Port)
+	check(c.Database.User != "", "database.user is required")
+	check(c.Database.Name != "", "database.name is required")
+
+	check(validPort(c.Server.Port), "server.port must be a port number, got %q", c.Server.Port)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Server.MaxDataAge > 0, "server.max_data_age must be positive")
+
+	check(validLanguage(c.Locale.Language), "locale.language must be ru or en, got %q", c.Locale.Language)
+	check(validLanguage(c.Locale.LogLanguage), "locale.log_language must be ru or en, got %q", c.Locale.LogLanguage)
+
+	check(c.Access.Mode == "open" || c.Access.Mode == "allowlist",
+		"access.mode must be open or allowlist, got %q", c.Access.Mode)
+	check(c.Access.Mode != "allowlist" || len(c.Access.Admins) > 0,
+		"access.admins (ADMIN_IDS) must not be empty in allowlist mode")
+
+	_, err := time.LoadLocation(c.Schedule.Timezone)
+	check(err == nil, "schedule.timezone: unknown time zone %q", c.Schedule.Timezone)
+	check(c.Schedule.Retention >= 24*time.Hour, "schedule.retention must be at least 24h")
+	check(c.Schedule.LeaderCheckInterval >= time.Second, "schedule.leader_check_interval must be at least 1s")
+	for name, job := range c.Schedule.Jobs {
+		if _, err := cron.Parse(job.Cron); err != nil {
+			errs = append(errs, fmt.Errorf("schedule.jobs.%s.cron: %w", name, err))
+		}
+		check(job.CatchUp == "" || job.CatchUp == CatchUpRunOnce || job.CatchUp == CatchUpSkip,
+			"schedule.jobs.%s.catch_up must be run_once or skip, got %q", name, job.CatchUp)
+	}
+
+	errs = append(errs, c.Analysis.Validate()...)
+
+	check(c.Budget.MinROI > 0, "budget.min_roi must be positive")
+	check(c.Budget.MaxPerItem > 0, "budget.max_per_item must be positive")
+	check(c.Budget.MaxItems > 0, "budget.max_items must be positive")
+	total := 0.0
+	for category, share := range c.Budget.Allocations {
+		check(share >= 0, "budget.allocations.%s must not be negative", category)
+		total += share
+	}
+	check(len(c.Budget.Allocations) > 0, "budget.allocations must not be empty")
+	check(c.Budget.HorizonDays > 0, "budget.horizon_days must be positive")
+	check(c.Budget.LiquidityShare > 0 && c.Budget.LiquidityShare <= 1, "budget.liquidity_share must be in (0, 1]")
+	check(c.Budget.Simulations >= 100 && c.Budget.Simulations <= 100000, "budget.simulations must be between 100 and 100000")
+	_, ok := c.Budget.Fees[c.Budget.Venue]
+	check(ok, "budget.fees has no entry for budget.venue %q", c.Budget.Venue)
+	for venue, fees := range c.Budget.Fees {
+		check(fees.Sale >= 0 && fees.Sale < 1 && fees.Withdraw >= 0 && fees.Withdraw < 1,
+			"budget.fees.%s must be in [0, 1)", venue)
+	}
+	check(c.Budget.SlippageImpact >= 0, "budget.slippage_impact must not be negative")
+	check(c.Budget.MaxSlippage >= 0 && c.Budget.MaxSlippage < 1, "budget.max_slippage must be in [0, 1)")
+	ra := c.Budget.RiskAversion
+	check(ra.High > 0 && ra.Medium >= ra.High && ra.Low >= ra.Medium,
+		"budget.risk_aversion must satisfy low >= medium >= high > 0")
+	check(total <= 1.0001, "budget.allocations must sum to at most 1, got %.2f", total)
+
+	return errors.Join(errs...)
+}
+
+// Проверка параметров анализа
+func (a AnalysisConfig) Validate() []error {
+	var errs []error
+	if a.HistoryDays < 1 {
+		errs = append(errs, fmt.Errorf("analysis.history_days must be positive"))
+	}
+	if a.BuyMinScore < 1 || a.BuyMinScore > 10 {
+		errs = append(errs, fmt.Errorf("analysis.buy_min_score must be in [1, 10], got %d", a.BuyMinScore))
+	}
+	if a.HoldMinScore < 1 || a.HoldMinScore > a.BuyMinScore {
+		errs = append(errs, fmt.Errorf("analysis.hold_min_score must be in [1, buy_min_score], got %d", a.HoldMinScore))
+	}
+	if a.TopMinScore < 1 || a.TopMinScore > 10 {
+		errs = append(errs, fmt.Errorf("analysis.top_min_score must be in [1, 10], got %d", a.TopMinScore))
+	}
+	if a.StickerMinScore < 1 || a.StickerMinScore > 10 {
+		errs = append(errs, fmt.Errorf("analysis.sticker_min_score must be in [1, 10], got %d", a.StickerMinScore))
+	}
+	for _, days := range a.RiskWindows {
+		if days < 2 || days > a.HistoryDays {
+			errs = append(errs, fmt.Errorf("analysis.risk_windows: %d must be in [2, history_days]", days))
+		}
+	}
+	if a.RiskConfidence <= 0.5 || a.RiskConfidence >= 1 {
+		errs = append(errs, fmt.Errorf("analysis.risk_confidence must be in (0.5, 1), got %v", a.RiskConfidence))
+	}
+	return errs
+}
+
+// Конфигурация в YAML со скрытыми секретами — для журналов
+func (c *Config) String() string {
+	safe := *c
+	hide := func(s *string) {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	hide(&safe.Telegram.Token)
+	hide(&safe.Telegram.WebhookSecret)
+	hide(&safe.Market.APIKey)
+	hide(&safe.Database.Password)
+	hide(&safe.Server.APIToken)
+
+	out, err := yaml.Marshal(&safe)
+	if err != nil {
+		return fmt.Sprintf("<config: %v>", err)
+	}
+	return string(out)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+func validLanguage(lang string) bool {
+	return lang == "ru" || lang == "en"
+}
+
+// Разбор списка ID через запятую
+func parseIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
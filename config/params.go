@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Параметры анализа и бюджета, которые перечитываются без перезапуска
+type Params struct {
+	Analysis AnalysisConfig `yaml:"analysis"`
+	Budget   BudgetConfig   `yaml:"budget"`
+}
+
+// Параметры в YAML — для журналов, ответа /reload и журнала анализов
+func (p *Params) String() string {
+	out, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Sprintf("<params: %v>", err)
+	}
+	return string(out)
+}
+
+// Текущий набор параметров. Читатели получают неизменяемый снимок,
+// Reload подменяет его целиком.
+type ParamsStore struct {
+	mu      sync.Mutex // сериализует перезагрузки
+	current atomic.Pointer[Params]
+}
+
+func NewParamsStore(cfg *Config) *ParamsStore {
+	s := &ParamsStore{}
+	s.current.Store(&Params{Analysis: cfg.Analysis, Budget: cfg.Budget})
+	return s
+}
+
+// Текущий снимок параметров; изменять его нельзя
+func (s *ParamsStore) Get() *Params {
+	return s.current.Load()
+}
+
+// Повторная загрузка конфигурации (файл и окружение). Применяются только
+// параметры анализа и бюджета; при ошибке проверки остаются прежние.
+func (s *ParamsStore) Reload() (*Params, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	params := &Params{Analysis: cfg.Analysis, Budget: cfg.Budget}
+	s.current.Store(params)
+	return params, nil
+}
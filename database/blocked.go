@@ -0,0 +1,34 @@
+package database
+
+// Отметка о чате, в который нельзя доставить сообщения
+func (db *DB) BlockChat(chatID int64, reason string) error {
+	query := `INSERT INTO blocked_chats (chat_id, reason) VALUES ($1, $2)
+			  ON CONFLICT (chat_id) DO UPDATE SET
+			  reason = $2, blocked_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(query, chatID, reason)
+	return err
+}
+
+func (db *DB) UnblockChat(chatID int64) error {
+	_, err := db.Exec(`DELETE FROM blocked_chats WHERE chat_id = $1`, chatID)
+	return err
+}
+
+func (db *DB) GetBlockedChats() ([]int64, error) {
+	rows, err := db.Query(`SELECT chat_id FROM blocked_chats`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chats []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, err
+		}
+		chats = append(chats, chatID)
+	}
+	return chats, rows.Err()
+}
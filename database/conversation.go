@@ -0,0 +1,36 @@
+package database
+
+import "time"
+
+type ChatState struct {
+	ChatID    int64
+	State     string
+	Data      string
+	ExpiresAt time.Time
+}
+
+// Текущее состояние диалога; sql.ErrNoRows если бот ничего не ждет
+func (db *DB) GetChatState(chatID int64) (*ChatState, error) {
+	s := &ChatState{ChatID: chatID}
+	err := db.QueryRow(`SELECT state, data, expires_at FROM chat_states WHERE chat_id = $1`, chatID).
+		Scan(&s.State, &s.Data, &s.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (db *DB) SetChatState(chatID int64, state, data string, expiresAt time.Time) error {
+	query := `INSERT INTO chat_states (chat_id, state, data, expires_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (chat_id) DO UPDATE SET
+			  state = $2, data = $3, expires_at = $4, updated_at = CURRENT_TIMESTAMP`
+
+	// TIMESTAMP хранится без зоны, поэтому пишем в UTC
+	_, err := db.Exec(query, chatID, state, data, expiresAt.UTC())
+	return err
+}
+
+func (db *DB) ClearChatState(chatID int64) error {
+	_, err := db.Exec(`DELETE FROM chat_states WHERE chat_id = $1`, chatID)
+	return err
+}
@@ -0,0 +1,61 @@
+package database
+
+import "database/sql"
+
+// Карточка предмета: последняя цена, анализ и метрики риска
+type ItemDetails struct {
+	ID           int           `json:"id"`
+	HashName     string        `json:"hash_name"`
+	MarketName   string        `json:"market_name"`
+	Category     string        `json:"category"`
+	ImageURL     string        `json:"image_url"`
+	CurrentPrice float64       `json:"current_price"`
+	DataPoints   int           `json:"data_points"`
+	Analysis     *ItemAnalysis `json:"analysis,omitempty"` // nil, если предмет еще не анализировался
+	Risk         []ItemRisk    `json:"risk"`
+}
+
+// Карточка предмета; sql.ErrNoRows, если предмета нет
+func (db *DB) GetItemDetails(itemID int) (*ItemDetails, error) {
+	// LEFT JOIN: предмет из поиска может еще не иметь анализа
+	query := `SELECT i.hash_name, i.market_name, i.category, i.image_url,
+			  ia.growth_rate, ia.volatility, ia.trend_score, ia.recommendation, ia.analysis_date,
+			  (SELECT price FROM price_history WHERE item_id = $1 ORDER BY recorded_at DESC LIMIT 1) as current_price,
+			  (SELECT COUNT(*) FROM price_history WHERE item_id = $1) as data_points
+			  FROM items i
+			  LEFT JOIN item_analysis ia ON i.id = ia.item_id
+			  WHERE i.id = $1`
+
+	d := &ItemDetails{ID: itemID}
+	var growthRate, volatility, currentPrice sql.NullFloat64
+	var trendScore sql.NullInt64
+	var recommendation sql.NullString
+	var analysisDate sql.NullTime
+
+	err := db.QueryRow(query, itemID).Scan(&d.HashName, &d.MarketName, &d.Category, &d.ImageURL,
+		&growthRate, &volatility, &trendScore, &recommendation, &analysisDate, &currentPrice, &d.DataPoints)
+	if err != nil {
+		return nil, err
+	}
+	d.CurrentPrice = currentPrice.Float64
+
+	if recommendation.Valid {
+		d.Analysis = &ItemAnalysis{
+			ItemID:         itemID,
+			GrowthRate:     growthRate.Float64,
+			Volatility:     volatility.Float64,
+			TrendScore:     int(trendScore.Int64),
+			Recommendation: recommendation.String,
+			AnalysisDate:   analysisDate.Time,
+		}
+	}
+
+	if d.Risk, err = db.GetItemRisk(itemID); err != nil {
+		return nil, err
+	}
+	if d.Risk == nil {
+		d.Risk = []ItemRisk{}
+	}
+	return d, nil
+}
+
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Состояние задачи по расписанию
+type JobState struct {
+	Name           string
+	Schedule       string
+	NextRunAt      time.Time
+	LastStartedAt  time.Time // нулевое, если задача не запускалась
+	LastFinishedAt time.Time
+	LastError      string
+}
+
+// Состояния всех задач по имени
+func (db *DB) GetJobStates() (map[string]JobState, error) {
+	rows, err := db.Query(`SELECT name, schedule, next_run_at, last_started_at, last_finished_at, last_error
+			  FROM scheduled_jobs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]JobState)
+	for rows.Next() {
+		var st JobState
+		var started, finished sql.NullTime
+		if err := rows.Scan(&st.Name, &st.Schedule, &st.NextRunAt, &started, &finished, &st.LastError); err != nil {
+			return nil, err
+		}
+		st.LastStartedAt = started.Time
+		st.LastFinishedAt = finished.Time
+		states[st.Name] = st
+	}
+	return states, rows.Err()
+}
+
+// Запись расписания и времени следующего запуска
+func (db *DB) SaveJobSchedule(name, schedule string, nextRunAt time.Time) error {
+	query := `INSERT INTO scheduled_jobs (name, schedule, next_run_at)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (name) DO UPDATE SET
+			    schedule = EXCLUDED.schedule,
+			    next_run_at = EXCLUDED.next_run_at,
+			    updated_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(query, name, schedule, nextRunAt.UTC())
+	return err
+}
+
+// Запись результата запуска (по расписанию или вручную)
+func (db *DB) SaveJobRun(name string, startedAt, finishedAt time.Time, errText string) error {
+	query := `INSERT INTO scheduled_jobs (name, schedule, next_run_at, last_started_at, last_finished_at, last_error)
+			  VALUES ($1, '', $3, $2, $3, $4)
+			  ON CONFLICT (name) DO UPDATE SET
+			    last_started_at = EXCLUDED.last_started_at,
+			    last_finished_at = EXCLUDED.last_finished_at,
+			    last_error = EXCLUDED.last_error,
+			    updated_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(query, name, startedAt.UTC(), finishedAt.UTC(), errText)
+	return err
+}
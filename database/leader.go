@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Ключи рекомендательных блокировок (pg_advisory_lock)
+const (
+	migrationLockKey int64 = 0x736b696e0001
+	leaderLockKey    int64 = 0x736b696e0002
+)
+
+// Блокировка лидера. Рекомендательная блокировка принадлежит сеансу,
+// поэтому держится на отдельном соединении и снимается сервером сама,
+// если процесс или соединение пропали.
+type LeaderLock struct {
+	conn *sql.Conn
+}
+
+// Попытка стать лидером без ожидания; (nil, nil), если лидер уже есть
+func (db *DB) TryLeaderLock(ctx context.Context) (*LeaderLock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, leaderLockKey).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !acquired {
+		conn.Close()
+		return nil, nil
+	}
+	return &LeaderLock{conn: conn}, nil
+}
+
+// Проверка, что сеанс с блокировкой жив
+func (l *LeaderLock) Check(ctx context.Context) error {
+	var held bool
+	query := `SELECT EXISTS (
+			    SELECT 1 FROM pg_locks
+			    WHERE locktype = 'advisory' AND pid = pg_backend_pid() AND granted AND objsubid = 1
+			      AND ((classid::bigint << 32) | objid::bigint) = $1)`
+	if err := l.conn.QueryRowContext(ctx, query, leaderLockKey).Scan(&held); err != nil {
+		return err
+	}
+	if !held {
+		return fmt.Errorf("leader lock is no longer held")
+	}
+	return nil
+}
+
+// Снятие блокировки и закрытие соединения
+func (l *LeaderLock) Release(ctx context.Context) error {
+	_, err := l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, leaderLockKey)
+	if cerr := l.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// Дневные свечи (OHLC) по истории цен за последние days дней (UTC),
+// включая текущий. Уже посчитанные дни пересчитываются, поэтому задачу
+// можно запускать повторно.
+func (db *DB) AggregateCandles(ctx context.Context, days int) (int64, error) {
+	query := `INSERT INTO price_candles (item_id, day, open, high, low, close, samples)
+			  SELECT item_id,
+			         recorded_at::date,
+			         (array_agg(price ORDER BY recorded_at))[1],
+			         MAX(price),
+			         MIN(price),
+			         (array_agg(price ORDER BY recorded_at DESC))[1],
+			         COUNT(*)
+			  FROM price_history
+			  WHERE recorded_at >= $1
+			  GROUP BY item_id, recorded_at::date
+			  ON CONFLICT (item_id, day) DO UPDATE SET
+			    open = EXCLUDED.open,
+			    high = EXCLUDED.high,
+			    low = EXCLUDED.low,
+			    close = EXCLUDED.close,
+			    samples = EXCLUDED.samples`
+
+	// recorded_at хранится в UTC, CURRENT_DATE зависит от часового пояса сессии
+	from := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	res, err := db.ExecContext(ctx, query, from)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Удаление журналов запусков, снимков и серий присутствия старше retention и истекших
+// состояний диалогов.
+// История цен и свечи не удаляются: они нужны для графиков и анализа.
+func (db *DB) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention).UTC()
+	statements := []struct {
+		query string
+		args  []interface{}
+	}{
+		{`DELETE FROM collection_runs WHERE started_at < $1`, []interface{}{cutoff}},
+		{`DELETE FROM analysis_runs WHERE started_at < $1`, []interface{}{cutoff}},
+		{`DELETE FROM price_snapshots WHERE fetched_at < $1`, []interface{}{cutoff}},
+		{`DELETE FROM item_presence WHERE last_seen < $1`, []interface{}{cutoff}},
+		{`DELETE FROM chat_states WHERE expires_at < $1`, []interface{}{time.Now().UTC()}},
+	}
+
+	var total int64
+	for _, stmt := range statements {
+		res, err := db.ExecContext(ctx, stmt.query, stmt.args...)
+		if err != nil {
+			return total, err
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, nil
+}
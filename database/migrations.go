@@ -0,0 +1,201 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// Миграции схемы, которые выполняются при старте приложения.
+// Каждая инструкция должна быть идемпотентной (IF NOT EXISTS),
+// новые изменения добавляются строго в конец списка.
+var migrations = []string{
+	// Портфель: сделки покупки и продажи пользователей
+	`CREATE TABLE IF NOT EXISTS portfolio_transactions (
+		id          SERIAL PRIMARY KEY,
+		user_id     BIGINT NOT NULL,
+		item_id     INTEGER NOT NULL REFERENCES items(id),
+		side        VARCHAR(4) NOT NULL CHECK (side IN ('BUY', 'SELL')),
+		quantity    INTEGER NOT NULL CHECK (quantity > 0),
+		price       NUMERIC(14, 2) NOT NULL CHECK (price >= 0),
+		venue       VARCHAR(64) NOT NULL DEFAULT '',
+		executed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_portfolio_transactions_user
+		ON portfolio_transactions (user_id, executed_at)`,
+
+	// Нечеткий поиск предметов по названию
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_items_market_name_trgm
+		ON items USING gin (market_name gin_trgm_ops)`,
+
+	// Настройки пользователей (язык интерфейса)
+	`CREATE TABLE IF NOT EXISTS user_settings (
+		user_id    BIGINT PRIMARY KEY,
+		language   VARCHAR(8) NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Состояние диалога в чате (чего бот ждет от пользователя)
+	`CREATE TABLE IF NOT EXISTS chat_states (
+		chat_id    BIGINT PRIMARY KEY,
+		state      VARCHAR(32) NOT NULL,
+		data       TEXT NOT NULL DEFAULT '',
+		expires_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Подписки на дайджест рынка; время следующей отправки хранится в UTC,
+	// поэтому расписание переживает перезапуск
+	`CREATE TABLE IF NOT EXISTS digest_subscriptions (
+		chat_id          BIGINT PRIMARY KEY,
+		frequency        VARCHAR(8) NOT NULL,
+		weekday          INTEGER NOT NULL DEFAULT 0,
+		send_time        VARCHAR(5) NOT NULL,
+		timezone         VARCHAR(64) NOT NULL,
+		next_run_at      TIMESTAMP NOT NULL,
+		last_sent_at     TIMESTAMP,
+		last_buy_items   INTEGER[] NOT NULL DEFAULT '{}',
+		last_portfolio   DOUBLE PRECISION,
+		created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_digest_subscriptions_next_run
+		ON digest_subscriptions (next_run_at)`,
+
+	// Чаты, в которые бот не может писать (заблокирован пользователем, удален из группы)
+	`CREATE TABLE IF NOT EXISTS blocked_chats (
+		chat_id    BIGINT PRIMARY KEY,
+		reason     TEXT NOT NULL,
+		blocked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Пользователи бота и их роли (admin, member, blocked)
+	`CREATE TABLE IF NOT EXISTS bot_users (
+		user_id    BIGINT PRIMARY KEY,
+		username   VARCHAR(64) NOT NULL DEFAULT '',
+		role       VARCHAR(16) NOT NULL DEFAULT 'member',
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Журнал запусков сбора цен
+	`CREATE TABLE IF NOT EXISTS collection_runs (
+		id             SERIAL PRIMARY KEY,
+		started_at     TIMESTAMP NOT NULL,
+		finished_at    TIMESTAMP NOT NULL,
+		items_received INTEGER NOT NULL DEFAULT 0,
+		items_stored   INTEGER NOT NULL DEFAULT 0,
+		items_failed   INTEGER NOT NULL DEFAULT 0,
+		error          TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_collection_runs_started_at
+		ON collection_runs (started_at DESC)`,
+
+	// Журнал запусков анализа с параметрами, по которым он выполнялся
+	`CREATE TABLE IF NOT EXISTS analysis_runs (
+		id             SERIAL PRIMARY KEY,
+		started_at     TIMESTAMP NOT NULL,
+		finished_at    TIMESTAMP NOT NULL,
+		items_analyzed INTEGER NOT NULL DEFAULT 0,
+		params         TEXT NOT NULL,
+		error          TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_analysis_runs_started_at
+		ON analysis_runs (started_at DESC)`,
+
+	// Задачи по расписанию: время следующего запуска и итог последнего
+	`CREATE TABLE IF NOT EXISTS scheduled_jobs (
+		name             VARCHAR(32) PRIMARY KEY,
+		schedule         VARCHAR(64) NOT NULL,
+		next_run_at      TIMESTAMP NOT NULL,
+		last_started_at  TIMESTAMP,
+		last_finished_at TIMESTAMP,
+		last_error       TEXT NOT NULL DEFAULT '',
+		updated_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Дневные свечи по истории цен
+	`CREATE TABLE IF NOT EXISTS price_candles (
+		item_id INTEGER NOT NULL REFERENCES items(id),
+		day     DATE NOT NULL,
+		open    NUMERIC(14, 2) NOT NULL,
+		high    NUMERIC(14, 2) NOT NULL,
+		low     NUMERIC(14, 2) NOT NULL,
+		close   NUMERIC(14, 2) NOT NULL,
+		samples INTEGER NOT NULL,
+		PRIMARY KEY (item_id, day)
+	)`,
+
+	// Снимки прайс-листа. heartbeat_seconds > 0 означает, что в снимке
+	// записаны только изменившиеся цены и контрольные строки
+	`CREATE TABLE IF NOT EXISTS price_snapshots (
+		id                SERIAL PRIMARY KEY,
+		source            VARCHAR(50) NOT NULL,
+		fetched_at        TIMESTAMP NOT NULL,
+		api_time          TIMESTAMP,
+		currency          VARCHAR(10) NOT NULL,
+		item_count        INTEGER NOT NULL,
+		stored_count      INTEGER NOT NULL DEFAULT 0,
+		heartbeat_seconds INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_price_snapshots_fetched_at
+		ON price_snapshots (fetched_at)`,
+	`ALTER TABLE price_history ADD COLUMN IF NOT EXISTS volume INTEGER`,
+	`ALTER TABLE price_history ADD COLUMN IF NOT EXISTS snapshot_id INTEGER`,
+	`CREATE INDEX IF NOT EXISTS idx_price_history_item_recorded
+		ON price_history (item_id, recorded_at DESC)`,
+	`ALTER TABLE items ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP`,
+
+	// Метрики риска по окнам, пересчитываются при каждом анализе
+	`CREATE TABLE IF NOT EXISTS item_risk (
+		item_id       INTEGER NOT NULL REFERENCES items(id),
+		window_days   INTEGER NOT NULL,
+		observations  INTEGER NOT NULL,
+		volatility    DOUBLE PRECISION NOT NULL,
+		value_at_risk DOUBLE PRECISION NOT NULL,
+		cvar          DOUBLE PRECISION NOT NULL,
+		confidence    DOUBLE PRECISION NOT NULL,
+		max_drawdown  DOUBLE PRECISION NOT NULL,
+		sharpe        DOUBLE PRECISION NOT NULL,
+		sortino       DOUBLE PRECISION NOT NULL,
+		computed_at   TIMESTAMP NOT NULL,
+		PRIMARY KEY (item_id, window_days)
+	)`,
+
+	// Присутствие предметов в прайс-листе: каждая строка — серия подряд
+	// идущих снимков источника, в которых предмет был. presence у снимка
+	// означает, что для него серии ведутся.
+	`CREATE TABLE IF NOT EXISTS item_presence (
+		item_id    INTEGER NOT NULL REFERENCES items(id),
+		source     VARCHAR(50) NOT NULL,
+		first_seen TIMESTAMP NOT NULL,
+		last_seen  TIMESTAMP NOT NULL,
+		PRIMARY KEY (item_id, source, first_seen)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_item_presence_last_seen
+		ON item_presence (source, last_seen)`,
+	`ALTER TABLE price_snapshots ADD COLUMN IF NOT EXISTS presence BOOLEAN NOT NULL DEFAULT FALSE`,
+}
+
+// Migrate применяет все миграции схемы по порядку
+func (db *DB) Migrate() error {
+	// Несколько экземпляров, запущенных одновременно, применяют миграции по очереди
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	for i, stmt := range migrations {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Сколько раз повторять сериализуемую транзакцию при конфликте
+const serializableAttempts = 3
+
+type PortfolioTransaction struct {
+	ID         int       `json:"id"`
+	UserID     int64     `json:"user_id"`
+	ItemID     int       `json:"item_id"`
+	MarketName string    `json:"market_name"`
+	Category   string    `json:"category"`
+	Side       string    `json:"side"` // BUY/SELL
+	Quantity   int       `json:"quantity"`
+	Price      float64   `json:"price"`
+	Venue      string    `json:"venue"`
+	ExecutedAt time.Time `json:"executed_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Точка дневной цены предмета (последняя цена за день)
+type DailyPrice struct {
+	ItemID int       `json:"item_id"`
+	Day    time.Time `json:"day"`
+	Price  float64   `json:"price"`
+}
+
+func (db *DB) AddPortfolioTransaction(tx *PortfolioTransaction) error {
+	query := `INSERT INTO portfolio_transactions (user_id, item_id, side, quantity, price, venue, executed_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  RETURNING id, created_at`
+
+	// TIMESTAMP хранится без зоны, поэтому пишем в UTC
+	tx.ExecutedAt = tx.ExecutedAt.UTC()
+	return db.QueryRow(query, tx.UserID, tx.ItemID, tx.Side, tx.Quantity, tx.Price,
+		tx.Venue, tx.ExecutedAt).Scan(&tx.ID, &tx.CreatedAt)
+}
+
+// Запись сделки, если ее допускает check — проверка по уже записанным
+// сделкам пользователя с тем же предметом (в хронологическом порядке).
+// Чтение и вставка идут в одной сериализуемой транзакции, поэтому две
+// одновременные продажи не пройдут проверку по одному и тому же остатку.
+func (db *DB) AddPortfolioTransactionChecked(tx *PortfolioTransaction, check func(existing []PortfolioTransaction) error) error {
+	// TIMESTAMP хранится без зоны, поэтому пишем в UTC
+	tx.ExecutedAt = tx.ExecutedAt.UTC()
+
+	var err error
+	for attempt := 0; attempt < serializableAttempts; attempt++ {
+		err = db.addPortfolioTransactionChecked(tx, check)
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != "40001" { // serialization_failure
+			return err
+		}
+	}
+	return err
+}
+
+func (db *DB) addPortfolioTransactionChecked(tx *PortfolioTransaction, check func(existing []PortfolioTransaction) error) error {
+	sqlTx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+	defer sqlTx.Rollback()
+
+	query := `SELECT id, user_id, item_id, side, quantity, price, venue, executed_at, created_at
+			  FROM portfolio_transactions
+			  WHERE user_id = $1 AND item_id = $2
+			  ORDER BY executed_at ASC, id ASC`
+
+	rows, err := sqlTx.Query(query, tx.UserID, tx.ItemID)
+	if err != nil {
+		return err
+	}
+	var existing []PortfolioTransaction
+	for rows.Next() {
+		var t PortfolioTransaction
+		if err := rows.Scan(&t.ID, &t.UserID, &t.ItemID, &t.Side, &t.Quantity, &t.Price,
+			&t.Venue, &t.ExecutedAt, &t.CreatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		existing = append(existing, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := check(existing); err != nil {
+		return err
+	}
+
+	insert := `INSERT INTO portfolio_transactions (user_id, item_id, side, quantity, price, venue, executed_at)
+			   VALUES ($1, $2, $3, $4, $5, $6, $7)
+			   RETURNING id, created_at`
+	if err := sqlTx.QueryRow(insert, tx.UserID, tx.ItemID, tx.Side, tx.Quantity, tx.Price,
+		tx.Venue, tx.ExecutedAt).Scan(&tx.ID, &tx.CreatedAt); err != nil {
+		return err
+	}
+	return sqlTx.Commit()
+}
+
+// Все сделки пользователя в хронологическом порядке
+func (db *DB) GetPortfolioTransactions(userID int64) ([]PortfolioTransaction, error) {
+	query := `SELECT pt.id, pt.user_id, pt.item_id, i.market_name, i.category, pt.side,
+			  pt.quantity, pt.price, pt.venue, pt.executed_at, pt.created_at
+			  FROM portfolio_transactions pt
+			  JOIN items i ON pt.item_id = i.id
+			  WHERE pt.user_id = $1
+			  ORDER BY pt.executed_at ASC, pt.id ASC`
+
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []PortfolioTransaction
+	for rows.Next() {
+		var tx PortfolioTransaction
+		err := rows.Scan(&tx.ID, &tx.UserID, &tx.ItemID, &tx.MarketName, &tx.Category, &tx.Side,
+			&tx.Quantity, &tx.Price, &tx.Venue, &tx.ExecutedAt, &tx.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+
+	return txs, rows.Err()
+}
+
+// Поиск предмета по точному названию (без учета регистра)
+func (db *DB) GetItemByName(name string) (*Item, error) {
+	query := `SELECT id, hash_name, market_name, class_id, instance_id, category, image_url, created_at, updated_at
+			  FROM items
+			  WHERE LOWER(hash_name) = LOWER($1) OR LOWER(market_name) = LOWER($1)
+			  ORDER BY id
+			  LIMIT 1`
+
+	var item Item
+	err := db.QueryRow(query, name).Scan(&item.ID, &item.HashName, &item.MarketName, &item.ClassID,
+		&item.InstanceID, &item.Category, &item.ImageURL, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Последние известные цены для набора предметов
+func (db *DB) GetLatestPrices(itemIDs []int) (map[int]float64, error) {
+	query := `SELECT DISTINCT ON (item_id) item_id, price
+			  FROM price_history
+			  WHERE item_id = ANY($1)
+			  ORDER BY item_id, recorded_at DESC`
+
+	rows, err := db.Query(query, pq.Array(itemIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[int]float64, len(itemIDs))
+	for rows.Next() {
+		var itemID int
+		var price float64
+		if err := rows.Scan(&itemID, &price); err != nil {
+			return nil, err
+		}
+		prices[itemID] = price
+	}
+
+	return prices, rows.Err()
+}
+
+// Дневные цены (последняя цена за каждый день) для набора предметов
+func (db *DB) GetDailyPrices(itemIDs []int, since time.Time) ([]DailyPrice, error) {
+	query := `SELECT DISTINCT ON (item_id, date_trunc('day', recorded_at))
+			  item_id, date_trunc('day', recorded_at) AS day, price
+			  FROM price_history
+			  WHERE item_id = ANY($1) AND recorded_at >= $2
+			  ORDER BY item_id, date_trunc('day', recorded_at), recorded_at DESC`
+
+	rows, err := db.Query(query, pq.Array(itemIDs), since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []DailyPrice
+	for rows.Next() {
+		var p DailyPrice
+		if err := rows.Scan(&p.ItemID, &p.Day, &p.Price); err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,68 @@
+package database
+
+import "time"
+
+// Метрики риска предмета за окно в днях. Доходности дневные
+// логарифмические; значения в процентах, кроме коэффициентов Sharpe и Sortino.
+type ItemRisk struct {
+	WindowDays   int       `json:"window_days"`
+	Observations int       `json:"observations"` // число дневных доходностей
+	Volatility   float64   `json:"volatility"`   // стандартное отклонение, % годовых
+	VaR          float64   `json:"var"`          // исторический VaR за день, % убытка
+	CVaR         float64   `json:"cvar"`         // средний убыток за пределами VaR, %
+	Confidence   float64   `json:"confidence"`   // уровень доверия VaR/CVaR
+	MaxDrawdown  float64   `json:"max_drawdown"` // максимальная просадка от пика, %
+	Sharpe       float64   `json:"sharpe"`       // годовой
+	Sortino      float64   `json:"sortino"`      // годовой
+	ComputedAt   time.Time `json:"computed_at"`
+}
+
+// Замена метрик риска предмета результатами последнего анализа
+func (db *DB) SaveItemRisk(itemID int, risks []ItemRisk) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM item_risk WHERE item_id = $1`, itemID); err != nil {
+		return err
+	}
+	for _, r := range risks {
+		_, err := tx.Exec(`INSERT INTO item_risk (item_id, window_days, observations, volatility, value_at_risk, cvar,
+				  confidence, max_drawdown, sharpe, sortino, computed_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			itemID, r.WindowDays, r.Observations, r.Volatility, r.VaR, r.CVaR,
+			r.Confidence, r.MaxDrawdown, r.Sharpe, r.Sortino, r.ComputedAt.UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Метрики риска предмета по возрастанию окна
+func (db *DB) GetItemRisk(itemID int) ([]ItemRisk, error) {
+	query := `SELECT window_days, observations, volatility, value_at_risk, cvar, confidence,
+			  max_drawdown, sharpe, sortino, computed_at
+			  FROM item_risk
+			  WHERE item_id = $1
+			  ORDER BY window_days`
+
+	rows, err := db.Query(query, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var risks []ItemRisk
+	for rows.Next() {
+		var r ItemRisk
+		if err := rows.Scan(&r.WindowDays, &r.Observations, &r.Volatility, &r.VaR, &r.CVaR, &r.Confidence,
+			&r.MaxDrawdown, &r.Sharpe, &r.Sortino, &r.ComputedAt); err != nil {
+			return nil, err
+		}
+		risks = append(risks, r)
+	}
+	return risks, rows.Err()
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Результат поиска предмета; у предмета может еще не быть анализа
+type ItemSearchResult struct {
+	ItemID         int     `json:"item_id"`
+	MarketName     string  `json:"market_name"`
+	Category       string  `json:"category"`
+	CurrentPrice   float64 `json:"current_price"`
+	TrendScore     int     `json:"trend_score"`    // 0 если анализа нет
+	Recommendation string  `json:"recommendation"` // пусто если анализа нет
+}
+
+// Сокращения состояний и модификаторов, которые пишут в поиске
+var searchAbbreviations = map[string]string{
+	"fn": "Factory New",
+	"mw": "Minimal Wear",
+	"ft": "Field-Tested",
+	"ww": "Well-Worn",
+	"bs": "Battle-Scarred",
+	"st": "StatTrak™",
+}
+
+// Совпадение с запросом: подстрока ($2, экранирована для LIKE) или
+// похожесть слов ($1)
+const searchCondition = `(i.market_name ILIKE '%' || $2 || '%' ESCAPE '\'
+			     OR $1 <% i.market_name)`
+
+// Нечеткий поиск по items.market_name (pg_trgm).
+// Возвращает страницу результатов и общее количество совпадений, в том
+// числе для страницы за концом выдачи.
+func (db *DB) SearchItems(text string, limit, offset int) ([]ItemSearchResult, int, error) {
+	search := normalizeSearchQuery(text)
+	if search == "" {
+		return nil, 0, nil
+	}
+
+	query := `SELECT i.id, i.market_name, i.category,
+			  (SELECT price FROM price_history WHERE item_id = i.id ORDER BY recorded_at DESC LIMIT 1) AS current_price,
+			  ia.trend_score, ia.recommendation,
+			  COUNT(*) OVER () AS total
+			  FROM items i
+			  LEFT JOIN item_analysis ia ON ia.item_id = i.id
+			  WHERE ` + searchCondition + `
+			  ORDER BY (i.market_name ILIKE $2 || '%' ESCAPE '\') DESC,
+			           word_similarity($1, i.market_name) DESC,
+			           similarity(i.market_name, $1) DESC,
+			           i.market_name
+			  LIMIT $3 OFFSET $4`
+
+	rows, err := db.Query(query, search, escapeLike(search), limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var results []ItemSearchResult
+	total := 0
+	for rows.Next() {
+		var r ItemSearchResult
+		var price sql.NullFloat64
+		var score sql.NullInt64
+		var recommendation sql.NullString
+
+		if err := rows.Scan(&r.ItemID, &r.MarketName, &r.Category, &price,
+			&score, &recommendation, &total); err != nil {
+			return nil, 0, err
+		}
+		r.CurrentPrice = price.Float64
+		r.TrendScore = int(score.Int64)
+		r.Recommendation = recommendation.String
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// Страница за концом выдачи пуста, и COUNT(*) OVER () не вернул
+	// общее количество: считаем его отдельно
+	if len(results) == 0 && offset > 0 {
+		err := db.QueryRow(`SELECT COUNT(*) FROM items i WHERE `+searchCondition,
+			search, escapeLike(search)).Scan(&total)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return results, total, nil
+}
+
+// Раскрывает сокращения ("ak redline ft" -> "ak redline Field-Tested")
+func normalizeSearchQuery(text string) string {
+	words := strings.Fields(text)
+	for i, w := range words {
+		if full, ok := searchAbbreviations[strings.ToLower(w)]; ok {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
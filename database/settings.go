@@ -0,0 +1,17 @@
+package database
+
+// Язык интерфейса пользователя; sql.ErrNoRows если язык не выбран
+func (db *DB) GetUserLanguage(userID int64) (string, error) {
+	var language string
+	err := db.QueryRow(`SELECT language FROM user_settings WHERE user_id = $1`, userID).Scan(&language)
+	return language, err
+}
+
+func (db *DB) SetUserLanguage(userID int64, language string) error {
+	query := `INSERT INTO user_settings (user_id, language) VALUES ($1, $2)
+			  ON CONFLICT (user_id) DO UPDATE SET
+			  language = $2, updated_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(query, userID, language)
+	return err
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Снимок прайс-листа: один запрос цен к источнику
+type PriceSnapshot struct {
+	ID          int
+	Source      string
+	FetchedAt   time.Time
+	APITime     time.Time // поле time ответа API; нулевое, если его нет
+	Currency    string
+	ItemCount   int           // предметов в ответе
+	StoredCount int           // записано строк цен
+	Heartbeat   time.Duration // 0 — записаны все предметы, иначе только изменения
+	Presence    bool          // состав снимка записан в item_presence
+}
+
+// Серия подряд идущих снимков источника, в которых был предмет
+type Presence struct {
+	Source    string
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Был ли предмет в снимке s
+func (p Presence) Contains(s PriceSnapshot) bool {
+	return p.Source == s.Source && !s.FetchedAt.Before(p.FirstSeen) && !s.FetchedAt.After(p.LastSeen)
+}
+
+// Последняя записанная цена предмета
+type LastPrice struct {
+	Price      float64
+	Volume     int
+	RecordedAt time.Time
+}
+
+func (db *DB) CreateSnapshot(s *PriceSnapshot) error {
+	var apiTime sql.NullTime
+	if !s.APITime.IsZero() {
+		apiTime = sql.NullTime{Time: s.APITime.UTC(), Valid: true}
+	}
+
+	query := `INSERT INTO price_snapshots (source, fetched_at, api_time, currency, item_count, heartbeat_seconds)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING id`
+
+	return db.QueryRow(query, s.Source, s.FetchedAt.UTC(), apiTime, s.Currency, s.ItemCount,
+		int(s.Heartbeat.Seconds())).Scan(&s.ID)
+}
+
+// Итог снимка: сколько строк цен записано
+func (db *DB) FinishSnapshot(id, stored int) error {
+	_, err := db.Exec(`UPDATE price_snapshots SET stored_count = $2 WHERE id = $1`, id, stored)
+	return err
+}
+
+// ID последнего снимка источника; 0, если снимков нет
+func (db *DB) GetLastSnapshotID(source string) (int, error) {
+	var id sql.NullInt64
+	err := db.QueryRow(`SELECT MAX(id) FROM price_snapshots WHERE source = $1`, source).Scan(&id)
+	return int(id.Int64), err
+}
+
+// Снимки всех источников начиная с since, по времени
+func (db *DB) GetSnapshots(since time.Time) ([]PriceSnapshot, error) {
+	query := `SELECT id, source, fetched_at, api_time, currency, item_count, stored_count, heartbeat_seconds, presence
+			  FROM price_snapshots
+			  WHERE fetched_at >= $1
+			  ORDER BY fetched_at`
+
+	rows, err := db.Query(query, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []PriceSnapshot
+	for rows.Next() {
+		var s PriceSnapshot
+		var apiTime sql.NullTime
+		var heartbeat int
+		if err := rows.Scan(&s.ID, &s.Source, &s.FetchedAt, &apiTime, &s.Currency,
+			&s.ItemCount, &s.StoredCount, &heartbeat, &s.Presence); err != nil {
+			return nil, err
+		}
+		s.APITime = apiTime.Time
+		s.Heartbeat = time.Duration(heartbeat) * time.Second
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// Последние записанные цены всех предметов источника
+func (db *DB) GetLastPrices(source string) (map[int]LastPrice, error) {
+	query := `SELECT DISTINCT ON (item_id) item_id, price, COALESCE(volume, 0), recorded_at
+			  FROM price_history
+			  WHERE source = $1
+			  ORDER BY item_id, recorded_at DESC`
+
+	rows, err := db.Query(query, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[int]LastPrice)
+	for rows.Next() {
+		var itemID int
+		var p LastPrice
+		if err := rows.Scan(&itemID, &p.Price, &p.Volume, &p.RecordedAt); err != nil {
+			return nil, err
+		}
+		prices[itemID] = p
+	}
+	return prices, rows.Err()
+}
+
+// Запись цены из снимка; время записи совпадает со временем снимка
+func (db *DB) AddSnapshotPrice(snapshot *PriceSnapshot, itemID int, price float64, volume int) error {
+	query := `INSERT INTO price_history (item_id, price, volume, currency, source, recorded_at, snapshot_id)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := db.Exec(query, itemID, price, volume, snapshot.Currency, snapshot.Source,
+		snapshot.FetchedAt.UTC(), snapshot.ID)
+	return err
+}
+
+// Отметка предметов, присутствовавших в снимке. Серия предмета
+// продлевается, если он был и в предыдущем снимке источника, иначе
+// начинается новая.
+func (db *DB) MarkItemsSeen(snapshot *PriceSnapshot, itemIDs []int) error {
+	seenAt := snapshot.FetchedAt.UTC()
+	if len(itemIDs) > 0 {
+		if _, err := db.Exec(`UPDATE items SET last_seen_at = $2 WHERE id = ANY($1)`, pq.Array(itemIDs), seenAt); err != nil {
+			return err
+		}
+
+		query := `WITH prev AS (
+					  SELECT MAX(fetched_at) AS fetched_at FROM price_snapshots
+					  WHERE source = $2 AND fetched_at < $3
+				  ), extended AS (
+					  UPDATE item_presence p SET last_seen = $3
+					  FROM prev
+					  WHERE p.source = $2 AND p.item_id = ANY($1) AND p.last_seen = prev.fetched_at
+					  RETURNING p.item_id
+				  )
+				  INSERT INTO item_presence (item_id, source, first_seen, last_seen)
+				  SELECT id, $2, $3, $3 FROM unnest($1::integer[]) AS id
+				  WHERE id NOT IN (SELECT item_id FROM extended)
+				  ON CONFLICT DO NOTHING`
+		if _, err := db.Exec(query, pq.Array(itemIDs), snapshot.Source, seenAt); err != nil {
+			return err
+		}
+	}
+
+	_, err := db.Exec(`UPDATE price_snapshots SET presence = TRUE WHERE id = $1`, snapshot.ID)
+	return err
+}
+
+// Серии присутствия предмета, заканчивающиеся не раньше since
+func (db *DB) GetItemPresence(itemID int, since time.Time) ([]Presence, error) {
+	query := `SELECT source, first_seen, last_seen FROM item_presence
+			  WHERE item_id = $1 AND last_seen >= $2
+			  ORDER BY first_seen`
+
+	rows, err := db.Query(query, itemID, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var presence []Presence
+	for rows.Next() {
+		var p Presence
+		if err := rows.Scan(&p.Source, &p.FirstSeen, &p.LastSeen); err != nil {
+			return nil, err
+		}
+		presence = append(presence, p)
+	}
+	return presence, rows.Err()
+}
+
+// Последний известный объем предметов; предметы без объема не попадают в результат
+func (db *DB) GetLatestVolumes(itemIDs []int) (map[int]int, error) {
+	query := `SELECT DISTINCT ON (item_id) item_id, volume
+			  FROM price_history
+			  WHERE item_id = ANY($1) AND volume IS NOT NULL
+			  ORDER BY item_id, recorded_at DESC`
+
+	rows, err := db.Query(query, pq.Array(itemIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	volumes := make(map[int]int, len(itemIDs))
+	for rows.Next() {
+		var itemID, volume int
+		if err := rows.Scan(&itemID, &volume); err != nil {
+			return nil, err
+		}
+		volumes[itemID] = volume
+	}
+	return volumes, rows.Err()
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Запуск сбора цен
+type CollectionRun struct {
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	ItemsReceived int
+	ItemsStored   int
+	ItemsFailed   int
+	Error         string
+}
+
+// Сводка по данным для /stats
+type Stats struct {
+	Items          int
+	PriceRows      int
+	AnalyzedItems  int
+	LastPriceAt    sql.NullTime
+	LastAnalysisAt sql.NullTime
+	LastRun        *CollectionRun // nil, если сбор еще не запускался
+	FailedRuns     int            // неудачных запусков за последние сутки
+	LastError      string         // последняя ошибка сбора
+	LastErrorAt    sql.NullTime
+}
+
+func (db *DB) RecordCollectionRun(startedAt, finishedAt time.Time, received, stored, failed int, errText string) error {
+	query := `INSERT INTO collection_runs (started_at, finished_at, items_received, items_stored, items_failed, error)
+			  VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := db.Exec(query, startedAt.UTC(), finishedAt.UTC(), received, stored, failed, errText)
+	return err
+}
+
+// Запись запуска анализа вместе с действовавшими параметрами (YAML)
+func (db *DB) RecordAnalysisRun(startedAt, finishedAt time.Time, analyzed int, params, errText string) error {
+	query := `INSERT INTO analysis_runs (started_at, finished_at, items_analyzed, params, error)
+			  VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := db.Exec(query, startedAt.UTC(), finishedAt.UTC(), analyzed, params, errText)
+	return err
+}
+
+// Последний запуск сбора; sql.ErrNoRows если сбор не запускался
+func (db *DB) GetLastCollectionRun() (*CollectionRun, error) {
+	run := &CollectionRun{}
+	err := db.QueryRow(`SELECT started_at, finished_at, items_received, items_stored, items_failed, error
+			  FROM collection_runs ORDER BY started_at DESC LIMIT 1`).
+		Scan(&run.StartedAt, &run.FinishedAt, &run.ItemsReceived, &run.ItemsStored, &run.ItemsFailed, &run.Error)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// Последний сбор без ошибки; sql.ErrNoRows если такого не было
+func (db *DB) GetLastSuccessfulCollectionRun() (*CollectionRun, error) {
+	run := &CollectionRun{}
+	err := db.QueryRow(`SELECT started_at, finished_at, items_received, items_stored, items_failed, error
+			  FROM collection_runs WHERE error = '' ORDER BY started_at DESC LIMIT 1`).
+		Scan(&run.StartedAt, &run.FinishedAt, &run.ItemsReceived, &run.ItemsStored, &run.ItemsFailed, &run.Error)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (db *DB) GetStats() (*Stats, error) {
+	s := &Stats{}
+
+	err := db.QueryRow(`SELECT
+			  (SELECT COUNT(*) FROM items),
+			  (SELECT COUNT(*) FROM price_history),
+			  (SELECT COUNT(*) FROM item_analysis),
+			  (SELECT MAX(recorded_at) FROM price_history),
+			  (SELECT MAX(analysis_date) FROM item_analysis)`).
+		Scan(&s.Items, &s.PriceRows, &s.AnalyzedItems, &s.LastPriceAt, &s.LastAnalysisAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.LastRun, err = db.GetLastCollectionRun()
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	err = db.QueryRow(`SELECT COUNT(*) FROM collection_runs
+			  WHERE error <> '' AND started_at >= $1`, time.Now().UTC().Add(-24*time.Hour)).Scan(&s.FailedRuns)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.QueryRow(`SELECT error, started_at FROM collection_runs
+			  WHERE error <> '' ORDER BY started_at DESC LIMIT 1`).Scan(&s.LastError, &s.LastErrorAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Подписка чата на дайджест рынка
+type DigestSubscription struct {
+	ChatID        int64
+	Frequency     string // daily или weekly
+	Weekday       time.Weekday
+	SendTime      string // ЧЧ:ММ в часовом поясе Timezone
+	Timezone      string
+	NextRunAt     time.Time
+	LastSentAt    sql.NullTime
+	LastBuyItems  []int64         // рекомендации BUY из прошлого дайджеста
+	LastPortfolio sql.NullFloat64 // стоимость портфеля в прошлом дайджесте
+}
+
+const subscriptionColumns = `chat_id, frequency, weekday, send_time, timezone,
+			  next_run_at, last_sent_at, last_buy_items, last_portfolio`
+
+func scanSubscription(row interface{ Scan(...interface{}) error }) (*DigestSubscription, error) {
+	var s DigestSubscription
+	var weekday int
+	err := row.Scan(&s.ChatID, &s.Frequency, &weekday, &s.SendTime, &s.Timezone,
+		&s.NextRunAt, &s.LastSentAt, pq.Array(&s.LastBuyItems), &s.LastPortfolio)
+	if err != nil {
+		return nil, err
+	}
+	s.Weekday = time.Weekday(weekday)
+	return &s, nil
+}
+
+// Создание или изменение подписки; состояние прошлого дайджеста сохраняется
+func (db *DB) SaveDigestSubscription(s *DigestSubscription) error {
+	query := `INSERT INTO digest_subscriptions (chat_id, frequency, weekday, send_time, timezone, next_run_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  ON CONFLICT (chat_id) DO UPDATE SET
+			  frequency = $2, weekday = $3, send_time = $4, timezone = $5, next_run_at = $6`
+
+	// TIMESTAMP хранится без зоны, поэтому пишем в UTC
+	_, err := db.Exec(query, s.ChatID, s.Frequency, int(s.Weekday), s.SendTime, s.Timezone, s.NextRunAt.UTC())
+	return err
+}
+
+// Подписка чата; sql.ErrNoRows если чат не подписан
+func (db *DB) GetDigestSubscription(chatID int64) (*DigestSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM digest_subscriptions WHERE chat_id = $1`
+	return scanSubscription(db.QueryRow(query, chatID))
+}
+
+func (db *DB) DeleteDigestSubscription(chatID int64) (bool, error) {
+	res, err := db.Exec(`DELETE FROM digest_subscriptions WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Подписки, время отправки которых наступило к моменту now
+func (db *DB) GetDueDigestSubscriptions(now time.Time, limit int) ([]*DigestSubscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM digest_subscriptions
+			  WHERE next_run_at <= $1
+			  ORDER BY next_run_at
+			  LIMIT $2`
+
+	rows, err := db.Query(query, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*DigestSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
+
+// Отметка об отправленном дайджесте и время следующей отправки
+func (db *DB) MarkDigestSent(chatID int64, sentAt, nextRunAt time.Time, buyItems []int64, portfolioValue float64) error {
+	query := `UPDATE digest_subscriptions SET
+			  last_sent_at = $2, next_run_at = $3, last_buy_items = $4, last_portfolio = $5
+			  WHERE chat_id = $1`
+
+	_, err := db.Exec(query, chatID, sentAt.UTC(), nextRunAt.UTC(), pq.Array(buyItems), portfolioValue)
+	return err
+}
+
+// Перенос следующей отправки без изменения состояния дайджеста (например, после ошибки)
+func (db *DB) RescheduleDigest(chatID int64, nextRunAt time.Time) error {
+	_, err := db.Exec(`UPDATE digest_subscriptions SET next_run_at = $2 WHERE chat_id = $1`,
+		chatID, nextRunAt.UTC())
+	return err
+}
@@ -0,0 +1,94 @@
+package database
+
+import "time"
+
+// Роли пользователей бота
+const (
+	RoleAdmin   = "admin"
+	RoleMember  = "member"
+	RoleBlocked = "blocked"
+)
+
+type BotUser struct {
+	UserID    int64
+	Username  string
+	Role      string
+	CreatedAt time.Time
+}
+
+// Роль пользователя; sql.ErrNoRows если пользователь неизвестен
+func (db *DB) GetUserRole(userID int64) (string, error) {
+	var role string
+	err := db.QueryRow(`SELECT role FROM bot_users WHERE user_id = $1`, userID).Scan(&role)
+	return role, err
+}
+
+// Регистрация пользователя при первом обращении; роль существующего не меняется
+func (db *DB) RegisterUser(userID int64, username, role string) error {
+	query := `INSERT INTO bot_users (user_id, username, role) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id) DO UPDATE SET
+			  username = $2, updated_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(query, userID, username, role)
+	return err
+}
+
+func (db *DB) SetUserRole(userID int64, role string) error {
+	query := `INSERT INTO bot_users (user_id, role) VALUES ($1, $2)
+			  ON CONFLICT (user_id) DO UPDATE SET
+			  role = $2, updated_at = CURRENT_TIMESTAMP`
+
+	_, err := db.Exec(query, userID, role)
+	return err
+}
+
+// ID пользователя по имени без @; sql.ErrNoRows если такого нет
+func (db *DB) FindUserByUsername(username string) (int64, error) {
+	var userID int64
+	err := db.QueryRow(`SELECT user_id FROM bot_users WHERE LOWER(username) = LOWER($1)`, username).Scan(&userID)
+	return userID, err
+}
+
+// Пользователи по роли (пустая роль — все), сначала новые
+func (db *DB) GetUsers(role string, limit int) ([]BotUser, error) {
+	query := `SELECT user_id, username, role, created_at FROM bot_users
+			  WHERE $1 = '' OR role = $1
+			  ORDER BY created_at DESC
+			  LIMIT $2`
+
+	rows, err := db.Query(query, role, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []BotUser
+	for rows.Next() {
+		var u BotUser
+		if err := rows.Scan(&u.UserID, &u.Username, &u.Role, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// Количество пользователей по ролям
+func (db *DB) CountUsersByRole() (map[string]int, error) {
+	rows, err := db.Query(`SELECT role, COUNT(*) FROM bot_users GROUP BY role`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var role string
+		var n int
+		if err := rows.Scan(&role, &n); err != nil {
+			return nil, err
+		}
+		counts[role] = n
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+// Время на проверку одного запроса
+const checkTimeout = 3 * time.Second
+
+// Обработчик /healthz: процесс жив и база данных отвечает
+func Liveness(db *database.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		defer cancel()
+
+		if err := db.PingContext(ctx); err != nil {
+			fail(w, fmt.Errorf("database: %w", err))
+			return
+		}
+		ok(w)
+	})
+}
+
+// Обработчик /readyz: процесс может обслуживать запросы — база отвечает.
+// Свежесть рыночных данных сюда не входит: при недоступном источнике
+// балансировщик вывел бы все реплики и бот перестал бы отвечать.
+func Readiness(db *database.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		defer cancel()
+
+		if err := db.PingContext(ctx); err != nil {
+			fail(w, fmt.Errorf("database: %w", err))
+			return
+		}
+		ok(w)
+	})
+}
+
+// Обработчик /marketz: последний успешный сбор цен был не раньше maxAge
+// назад. Для мониторинга и алертов, не для балансировщика.
+func MarketFreshness(db *database.DB, maxAge time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		run, err := db.GetLastSuccessfulCollectionRun()
+		if errors.Is(err, sql.ErrNoRows) {
+			fail(w, errors.New("no successful collection yet"))
+			return
+		}
+		if err != nil {
+			fail(w, fmt.Errorf("database: %w", err))
+			return
+		}
+
+		if age := time.Since(run.FinishedAt); age > maxAge {
+			fail(w, fmt.Errorf("last successful collection was %v ago", age.Round(time.Second)))
+			return
+		}
+		ok(w)
+	})
+}
+
+func ok(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ok")
+}
+
+func fail(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	fmt.Fprintln(w, err)
+}
@@ -0,0 +1,430 @@
+package i18n
+
+var en = map[string]string{
+	// Общие сообщения
+	"common.unknown_command": "Unknown command. Use /start for help.",
+	"common.data_error":      "Failed to load data. Please try again later.",
+	"common.try_later":       "❌ Something went wrong. Please try again later.",
+
+	"welcome": `🎮 <b>Welcome to CS2 Skin Analyzer!</b>
+
+This bot helps you find promising skins to invest in.
+
+📊 <b>Available commands:</b>
+/top - Top promising skins (paginated)
+/budget - Build an optimal investment portfolio
+/portfolio - Your portfolio and P&amp;L
+/buy, /sell - Record a purchase or sale
+/search - Find an item by name (or just type the name)
+/subscribe - Daily or weekly market digest
+/language - Change language
+
+🚀 <b>How it works:</b>
+The bot analyzes skin price trends and assigns a score from 1 to 10, where 10 is the most promising item to buy.
+
+💡 <b>Recommendations:</b>
+• 🟢 BUY - recommended to buy
+• 🟡 HOLD - hold if you own it
+• 🔴 SELL - recommended to sell
+
+🔍 <b>Navigation:</b>
+• Tap an item for a detailed analysis
+• Use ⬅️➡️ to switch pages`,
+
+	// Язык
+	"language.name":          "🇬🇧 English",
+	"language.choose":        "🌐 Choose your language:",
+	"language.changed":       "✅ Language switched to English",
+	"language.changed_group": "✅ Language switched to English for your private chat with the bot and inline mode. In groups the bot replies in the default language.",
+
+	// Единицы с формами множественного числа
+	"unit.point": "data point|data points",
+	"unit.item":  "item|items",
+	"unit.lot":   "lot|lots",
+
+	// Категории
+	"category.knives":         "Knives",
+	"category.weapons":        "Weapons",
+	"category.containers":     "Cases",
+	"category.keys":           "Keys",
+	"category.packages":       "Packages",
+	"category.gloves":         "Gloves",
+	"category.stickers":       "Stickers",
+	"category.charms":         "Charms",
+	"category.other":          "Other",
+	"category.knives.top":     "TOP Knives",
+	"category.weapons.top":    "TOP Weapons",
+	"category.containers.top": "TOP Cases",
+	"category.keys.top":       "TOP Keys",
+	"category.packages.top":   "TOP Packages",
+	"category.gloves.top":     "TOP Gloves",
+	"category.stickers.top":   "TOP Stickers",
+	"category.charms.top":     "TOP Charms",
+	"category.all.top":        "TOP All categories",
+
+	// Топ предметов
+	"top.menu": "📂 <b>Choose a category to analyze:</b>\n\n" +
+		"🔪 Knives - the most expensive and stable investments\n" +
+		"🔫 Weapons - popular skins with good potential\n" +
+		"📦 Cases - skin containers (Case only)\n" +
+		"🧤 Gloves - rare and valuable items\n" +
+		"🗝️ Keys - for opening cases\n" +
+		"📤 Packages - capsules and souvenirs\n" +
+		"🏷️ Stickers - collectible value\n" +
+		"🎯 Charms - a new item category\n" +
+		"⭐ All categories - overall top",
+	"top.all_button": "⭐ All categories",
+	"top.empty":      "No analyzed items in this category yet. Analysis refreshes automatically, please check back later.",
+	"top.page_title": "🏆 <b>%s</b> (page %d/%d)\n\n",
+	"top.item_line":  "   📊 Score: %d/10 | 💰 %.2f ₽ | 📈 %.1f%%\n",
+
+	// Навигация
+	"nav.prev":            "⬅️ Back",
+	"nav.next":            "Next ➡️",
+	"nav.choose_category": "📂 Choose category",
+	"nav.back_to_list":    "⬅️ Back to list",
+
+	"callback.stale":     "This button has expired. Please open the menu again.",
+	"callback.malformed": "Could not process this button. Please open the menu again.",
+
+	// Анализ
+	"analyze.started":  "🔄 Starting market analysis... This may take a few minutes.",
+	"analyze.failed":   "❌ Analysis failed: %s",
+	"analyze.done":     "✅ Analysis complete! Use /top to see the results.",
+	"analyze.joined":   "⏳ Analysis is already running (%.0f%%), I'll let you know when it finishes.",
+	"analyze.progress": "🔄 Market analysis: %.0f%%",
+
+	// Карточка предмета
+	"item.error":          "Failed to load item details.",
+	"item.title":          "📊 <b>Detailed investment analysis</b>\n\n",
+	"item.category":       "📂 Category: %s\n\n",
+	"item.price":          "💰 Price: %.2f ₽\n",
+	"item.growth":         "📈 Growth: %.1f%% over the period\n",
+	"item.volatility":     "📊 Volatility: %.1f%%\n",
+	"item.score":          "⭐ Score: %d/10\n",
+	"item.risk":           "📉 Risk over %d days: volatility %.0f%% p.a., VaR %.0f%% — %.1f%%, CVaR %.1f%%, max drawdown %.1f%%, Sharpe %.2f, Sortino %.2f\n",
+	"item.recommendation": "%s Recommendation: %s\n\n",
+	"item.why":            "🔍 <b>Why consider it:</b>\n",
+	"item.strategy":       "\n📈 <b>Investment strategy:</b>\n",
+	"item.not_analyzed":   "\n⚪ This item has not been analyzed yet - the score will appear after the next analysis.\n",
+	"item.data_points":    "\n📊 Data reliability: %s\n",
+
+	// Советы
+	"advice.strong_buy": "Strong buy - excellent growth potential!",
+	"advice.cheap":      "Cheap asset with good prospects",
+	"advice.premium":    "Stable high-value investment",
+	"advice.moderate":   "Moderate potential, good for diversification",
+	"advice.risky":      "Risky investment, needs research",
+
+	"analysis.score_high":          "✅ A high score indicates strong growth prospects\n",
+	"analysis.score_medium":        "⚡ Average score - the item has potential\n",
+	"analysis.growth_fast":         "🚀 Shows strong price growth\n",
+	"analysis.growth_steady":       "📈 Steady positive trend\n",
+	"analysis.price_low":           "💎 Low entry price - minimal risk\n",
+	"analysis.price_premium":       "💰 Premium segment - for serious investors\n",
+	"analysis.category.knives":     "🔪 Knives - the most stable category for long-term investing\n",
+	"analysis.category.weapons":    "🔫 Weapons - high liquidity and demand\n",
+	"analysis.category.containers": "📦 Cases - appreciate over time\n",
+	"analysis.category.gloves":     "🧤 Gloves - a rare category with limited supply\n",
+	"analysis.volatility_low":      "🛡️ Stable price - low risk of losses\n",
+	"analysis.volatility_high":     "⚡ High volatility - rapid changes are possible\n",
+
+	"strategy.buy":                 "🟢 <b>Buy now</b> - a good entry point\n",
+	"strategy.buy_several":         "💡 Consider buying several for diversification\n",
+	"strategy.buy_hold":            "⏰ Recommended holding period: 3-6 months\n",
+	"strategy.hold":                "🟡 <b>Hold</b> - if you already own it\n📊 Watch the trend, consider buying on a dip\n",
+	"strategy.sell":                "🔴 <b>Sell</b> - high risk of decline\n💸 Consider taking profit if you have any\n",
+	"strategy.category.knives":     "🔪 Prefer knives in good condition (MW, FN)\n",
+	"strategy.category.containers": "📦 Cases are a long game - hold for at least a year\n",
+	"strategy.category.weapons":    "🔫 Popular weapons (AK, M4, AWP) are preferable\n",
+
+	// Калькулятор бюджета
+	"budget.intro": `💰 <b>Budget calculator</b>
+
+We will build an optimal portfolio for your capital from items with a net return of at least <b>%+.0f%%</b> — after the %s fee, withdrawal costs and slippage when selling.
+
+🎯 <b>What the calculator does:</b>
+• Analyzes top items with the best forecasts
+• Estimates return and risk from price history
+• Deducts venue fees and losses from selling into thin volume
+• Splits the budget considering correlations, liquidity and the chosen risk level
+• Shows expected profit and its spread
+
+💡 <b>Enter your budget in rubles:</b>
+For example: 10000`,
+	"budget.custom_button": "💬 Enter amount",
+	"budget.use_command":   "💡 To build a portfolio for this amount, open the calculator with /budget and enter your budget.",
+	"budget.custom_prompt": "💰 Enter your budget in rubles as a number:\nFor example: 15000",
+	"budget.min":           "❌ Minimum budget: 1000₽",
+	"budget.max":           "❌ Maximum budget: 10,000,000₽",
+	"budget.error":         "❌ Failed to calculate the portfolio. Please try again later.",
+	"budget.nothing_found": "❌ No items found with a net return of at least %+.0f%% after %s fees and slippage",
+	"budget.title":         "💰 <b>Optimal portfolio for %s₽</b>\n\n",
+	"budget.stats": "📊 <b>Summary:</b>\n" +
+		"💵 To invest: %s₽\n" +
+		"💰 Remaining: %s₽\n" +
+		"📈 Expected profit after costs: %s₽\n" +
+		"🎯 Total ROI: %+.1f%%\n",
+	"budget.costs":              "🧾 Selling on %s: fee %.1f%%, withdrawal %.1f%%, plus slippage\n\n",
+	"budget.risk":               "⚖️ Risk: %s. Profit spread over %d days: ±%s₽\n\n",
+	"budget.risk_low":           "low",
+	"budget.risk_medium":        "medium",
+	"budget.risk_high":          "high",
+	"budget.risk_button_low":    "🛡 More cautious",
+	"budget.risk_button_medium": "⚖️ Medium risk",
+	"budget.risk_button_high":   "🚀 More aggressive",
+	"budget.simulation": "🎲 <b>Simulation over %d days:</b>\n" +
+		"   median: %s\n" +
+		"   bad case (5%%): %s\n" +
+		"   good case (95%%): %s\n" +
+		"   probability of loss: %.0f%%\n\n",
+	"budget.simulation_caption": "🎲 %d portfolio value scenarios over %d days based on historical price moves",
+	"budget.purchases":          "🛒 <b>Recommended purchases:</b>\n\n",
+	"budget.item_cost":          "   💸 %s₽ × %d pcs = %s₽\n",
+	"budget.item_roi":           "   📈 ROI: %+.1f%% (+%s₽), price growth %+.1f%%\n",
+	"budget.item_score":         "   ⭐ Score: %d/10\n\n",
+	"budget.disclaimer": "⚠️ <b>Important:</b>\n" +
+		"• This is a forecast, actual returns may differ\n" +
+		"• Only invest money you can afford to lose\n" +
+		"• Recommended holding period: 6-12 months\n" +
+		"• Keep an eye on game updates and the market",
+	"budget.new_button": "🔄 New calculation",
+	"budget.top_button": "📊 Top items",
+	"budget.again":      "💡 Want to recalculate with a different budget or risk level?",
+
+	// Портфель
+	"portfolio.entry_prompt": "✍️ Enter the trade as:\nName; quantity; price; [venue]; [date YYYY-MM-DD]",
+	"portfolio.usage": `Trade format:
+/buy Name; quantity; price; [venue]; [date YYYY-MM-DD]
+/sell Name; quantity; price; [venue]; [date YYYY-MM-DD]
+
+Example:
+/buy AK-47 | Redline (Field-Tested); 2; 850; market.csgo.com; 2024-05-01`,
+	"portfolio.bad_quantity":   "❌ Quantity must be a positive whole number.",
+	"portfolio.bad_price":      "❌ Invalid price.",
+	"portfolio.bad_date":       "❌ Date must be in YYYY-MM-DD format.",
+	"portfolio.unknown_item":   "❌ Item not found. Use the exact market name.",
+	"portfolio.insufficient":   "❌ You cannot sell more than you hold.",
+	"portfolio.save_failed":    "❌ Failed to save the trade. Please try again later.",
+	"portfolio.side.buy":       "Purchase",
+	"portfolio.side.sell":      "Sale",
+	"portfolio.recorded":       "✅ %s recorded\n\n%s\n%d pcs × %.2f ₽ = %.2f ₽\n🏪 %s, %s\n\nSee /portfolio",
+	"portfolio.error":          "❌ Failed to calculate the portfolio. Please try again later.",
+	"portfolio.empty":          "💼 Your portfolio is empty.",
+	"portfolio.title":          "💼 Your portfolio\n\n",
+	"portfolio.cost_basis":     "💵 Invested (open positions): %.2f ₽\n",
+	"portfolio.market_value":   "💰 Current value: %.2f ₽\n",
+	"portfolio.unrealized":     "📈 Unrealized P&L: %s\n",
+	"portfolio.realized":       "✅ Realized P&L: %+.2f ₽\n\n",
+	"portfolio.lots":           "🧾 Lots:\n",
+	"portfolio.more_lots":      "… and %s more\n",
+	"portfolio.lot_line":       "   %s, %s: %d/%d pcs at %.2f ₽ → %.2f ₽\n",
+	"portfolio.lot_unrealized": "   Unrealized: %s\n",
+	"portfolio.lot_realized":   "   Realized: %+.2f ₽\n",
+	"portfolio.allocation":     "\n📊 Allocation by category:\n",
+	"portfolio.chart_caption":  "📈 Portfolio value over 30 days",
+
+	// Поиск
+	"search.prompt":          "🔍 Enter an item name:\nFor example: ak redline ft",
+	"search.expired":         "🔍 This search has expired, please run /search again",
+	"search.error":           "Search failed. Please try again later.",
+	"search.nothing":         "🔍 Nothing found for “%s”.",
+	"search.title":           "🔍 Search results for “%s”: %s (page %d/%d)\n\n",
+	"search.line":            "   %s Score: %d/10 | 💰 %.2f ₽ | 7d: %+.1f%%\n",
+	"search.line_unanalyzed": "   ⚪ Not analyzed | 💰 %.2f ₽ | 7d: %+.1f%%\n",
+
+	// Inline-режим
+	"inline.open_bot":       "Open the bot",
+	"inline.summary":        "💰 %.2f ₽ | 7d: %+.1f%%",
+	"inline.week_change":    "📈 7 days: %+.1f%%\n",
+	"inline.recommendation": "%s Recommendation: %s\n",
+	"inline.not_analyzed":   "⚪ This item has not been analyzed yet\n",
+
+	// Графики
+	"chart.axis.date":         "Date",
+	"chart.axis.price":        "Price (₽)",
+	"chart.axis.value":        "Value (₽)",
+	"chart.price.title":       "Price history",
+	"chart.price.series":      "Price",
+	"chart.price.trend":       "Trend",
+	"chart.portfolio.title":   "Portfolio value",
+	"chart.portfolio.series":  "Portfolio",
+	"chart.axis.days":         "Days",
+	"chart.simulation.title":  "Portfolio value scenarios",
+	"chart.simulation.p95":    "95th percentile",
+	"chart.simulation.p5":     "5th percentile",
+	"chart.simulation.median": "Median",
+	"chart.simulation.cost":   "Invested",
+
+	// Дайджест
+	"subscribe.usage": `Format:
+/subscribe daily 09:00 [time zone]
+/subscribe weekly mon 09:00 [time zone]
+
+The default time zone is Europe/Moscow, e.g. Europe/Berlin, America/New_York.`,
+	"subscribe.none":         "📰 You are not subscribed to the market digest.",
+	"subscribe.status":       "📰 Digest: %s.\nNext one: %s.\n\nChange: /subscribe, turn off: /unsubscribe",
+	"subscribe.saved":        "✅ Subscribed: %s.\nNext digest: %s.",
+	"subscribe.daily":        "daily at %s (%s)",
+	"subscribe.weekly":       "weekly on %s at %s (%s)",
+	"subscribe.bad_time":     "❌ Please specify the time as HH:MM.",
+	"subscribe.bad_weekday":  "❌ Please specify a weekday: mon, tue, wed, thu, fri, sat or sun.",
+	"subscribe.bad_timezone": "❌ Unknown time zone.",
+	"unsubscribe.done":       "✅ Digest subscription turned off.",
+	"weekday.0":              "Sunday",
+	"weekday.1":              "Monday",
+	"weekday.2":              "Tuesday",
+	"weekday.3":              "Wednesday",
+	"weekday.4":              "Thursday",
+	"weekday.5":              "Friday",
+	"weekday.6":              "Saturday",
+	"digest.title.daily":     "📰 <b>Daily market digest</b> (%s)\n\n",
+	"digest.title.weekly":    "📰 <b>Weekly market digest</b> (%s)\n\n",
+	"digest.movers":          "📊 <b>Top gainers and losers</b>\n",
+	"digest.no_movers":       "No notable price changes.\n",
+	"digest.gainer":          "   📈 %s: %+.1f%% (%.2f ₽)\n",
+	"digest.loser":           "   📉 %s: %+.1f%% (%.2f ₽)\n",
+	"digest.new_buys":        "\n🟢 <b>New BUY recommendations</b>\n",
+	"digest.no_new_buys":     "No new recommendations.\n",
+	"digest.buy_line":        "   • %s — ⭐ %d/10, %.2f ₽\n",
+	"digest.portfolio":       "\n💼 <b>Portfolio:</b> %.2f ₽\n",
+	"digest.portfolio_delta": "   Since last digest: %+.2f ₽ (%+.1f%%)\n",
+	"digest.footer":          "\nUnsubscribe: /unsubscribe",
+
+	// Доступ и администрирование
+	"access.denied":       "⛔ Access to this bot is restricted. Send your ID to an administrator: %d",
+	"access.denied_short": "⛔ Access restricted",
+	"access.admin_only":   "⛔ This command is available to administrators only.",
+	"welcome.admin": `
+
+🛠 <b>Administration:</b>
+/analyze - Run market analysis
+/collect - Collect prices now
+/stats - Data and collection statistics
+/users [role] - List users
+/setrole ID|@name role - Assign a role (admin, member, blocked)
+/reload - Reload analysis and budget parameters
+/jobs - Job schedule and last runs`,
+	"stats.title":             "📊 <b>Statistics</b>\n\n",
+	"stats.rows":              "📦 Items: %d\n💾 Price records: %d\n🔍 Analyzed: %d\n",
+	"stats.last_price":        "🕒 Latest price: %s\n",
+	"stats.last_analysis":     "🕒 Latest analysis: %s\n",
+	"stats.last_run":          "\n🛰 Last collection: %s (%s, %.0f s)\n   Received: %d, stored: %d, write errors: %d\n",
+	"stats.no_runs":           "\n🛰 Collection has not run yet\n",
+	"stats.run_ok":            "succeeded",
+	"stats.run_failed":        "failed",
+	"reload.done":             "🔄 Parameters reloaded:\n",
+	"reload.failed":           "❌ Parameters were not reloaded, the previous ones stay active:\n",
+	"alert.collect_failing":   "⚠️ Price collection from %s failed %d times in a row (%s): %s\nThe source is paused, next attempt at %s.",
+	"alert.collect_auth":      "🔑 %s rejects the API key: %s\nCollection is paused, next attempt at %s. Check MARKET_API_KEY.",
+	"alert.collect_recovered": "✅ Price collection from %s has recovered.",
+	"stats.failed_runs":       "❗ Failed collections in the last 24h: %d\n",
+	"stats.last_error":        "❗ Last error (%s): %s\n",
+	"stats.users":             "\n👥 Users: %d admins, %d members, %d blocked\n",
+	"stats.never":             "—",
+	"jobs.title":              "🗓 <b>Scheduled jobs</b>\n",
+	"jobs.empty":              "🗓 The schedule has not been saved yet.",
+	"jobs.item":               "\n<b>%s</b> <code>%s</code>\n   Next run: %s\n",
+	"jobs.running":            "   ⏳ Running: %.0f%%\n",
+	"jobs.never_run":          "   Never run yet\n",
+	"jobs.not_leader":         "⚠️ Jobs run on the leader instance, and this instance is not the leader right now. Try again later or send the command to the leader.",
+	"jobs.last_ok":            "   ✅ Last run: %s (%v)\n",
+	"jobs.last_failed":        "   ❌ Last run: %s (%v): %s\n",
+	"collect.started":         "🛰 Starting price collection...",
+	"collect.done":            "✅ Collection finished: received %d, stored %d, write errors %d.",
+	"collect.failed":          "❌ Collection failed: %s",
+	"collect.joined":          "⏳ Price collection is already running (%.0f%%), I'll let you know when it finishes.",
+	"collect.progress":        "🛰 Price collection: %.0f%%",
+	"collect.done_short":      "✅ Collection finished.",
+	"users.title":             "👥 Users:\n\n",
+	"users.line":              "%d %s — %s (since %s)\n",
+	"users.hint":              "\nChange a role: /setrole ID role",
+	"users.empty":             "No users found.",
+	"users.bad_role":          "❌ Role must be one of: admin, member, blocked.",
+	"setrole.usage":           "Usage: /setrole ID|@name admin|member|blocked",
+	"setrole.unknown_user":    "❌ User not found. Please use the numeric ID.",
+	"setrole.config_admin":    "❌ This administrator is defined in the configuration (ADMIN_IDS).",
+	"setrole.done":            "✅ User %d now has the role %s.",
+
+	// Журналы
+	"log.config.invalid":             "Invalid configuration:\n%v",
+	"log.config.loaded":              "Configuration:\n%s",
+	"log.db.connect_failed":          "Database connection failed: %v",
+	"log.db.migrate_failed":          "Database migration failed: %v",
+	"log.digest.load_failed":         "Failed to load digest data: %v",
+	"log.digest.save_failed":         "Failed to save subscription %d: %v",
+	"log.digest.send_failed":         "Failed to send digest to chat %d: %v",
+	"log.digest.sent":                "Digests sent: %d of %d",
+	"log.send.retry":                 "Retrying send to chat %d (attempt %d, in %v): %v",
+	"log.send.failed":                "Failed to deliver message to chat %d: %v",
+	"log.send.blocked":               "Chat %d is unreachable, stopped sending: %s",
+	"log.send.unblocked":             "Chat %d is reachable again",
+	"log.send.block_save_failed":     "Failed to save chat %d status: %v",
+	"log.send.blocked_load_failed":   "Failed to load unreachable chats: %v",
+	"log.access.load_failed":         "Failed to load role for user %d: %v",
+	"log.access.save_failed":         "Failed to save user %d: %v",
+	"log.access.stats_failed":        "Failed to load statistics: %v",
+	"log.access.role_changed":        "User %d was assigned role %s (by admin %d)",
+	"log.collect.record_failed":      "Failed to record collection run: %v",
+	"log.params.reloaded":            "Analysis and budget parameters reloaded (%v)",
+	"log.params.reload_failed":       "Failed to reload parameters: %v",
+	"log.params.active":              "Active parameters:\n%s",
+	"log.analysis.record_failed":     "Failed to record analysis run: %v",
+	"log.bot.create_failed":          "Failed to create bot: %v",
+	"log.bot.authorized":             "Authorized as %s",
+	"log.bot.started":                "🤖 Bot is up and running!",
+	"log.bot.language_save_failed":   "Failed to save language for %d: %v",
+	"log.bot.webhook_registered":     "Webhook registered: %s",
+	"log.bot.webhook_bad_update":     "Malformed webhook update: %v",
+	"log.bot.webhook_failed":         "Failed to register webhook: %v",
+	"log.bot.callback_encode_failed": "Failed to encode callback %s: %v",
+	"log.bot.state_save_failed":      "Failed to save conversation state for %d: %v",
+	"log.bot.state_load_failed":      "Failed to load conversation state for %d: %v",
+	"log.bot.callback_malformed":     "Malformed callback %q: %v",
+	"log.http.listening":             "HTTP server listening on port %s",
+	"log.api.failed":                 "API request %s failed: %v",
+	"log.budget.failed":              "Failed to calculate portfolio for chat %d: %v",
+	"log.budget.chart_failed":        "Failed to render simulation chart for chat %d: %v",
+	"log.http.failed":                "HTTP server error: %v",
+	"log.http.shutdown_failed":       "Failed to stop HTTP server: %v",
+	"log.bot.stopped":                "Update processing stopped",
+	"log.shutdown.started":           "Shutdown signal received, stopping...",
+	"log.shutdown.done":              "Shutdown complete",
+	"log.shutdown.timeout":           "Failed to shut down cleanly within %v, exiting",
+	"log.jobs.started":               "▶️ Job %s started",
+	"log.jobs.finished":              "✅ Job %s finished in %v",
+	"log.jobs.failed":                "❌ Job %s failed after %v: %v",
+	"log.scheduler.scheduled":        "🗓 Job %s scheduled as %q, next run at %s",
+	"log.scheduler.skipped":          "⏭ Job %s is still running, scheduled run skipped",
+	"log.scheduler.idle":             "No jobs have a next run, scheduler is waiting for shutdown",
+	"log.scheduler.invalid":          "Invalid schedule for job %s: %v",
+	"log.scheduler.state_failed":     "Failed to load job state: %v",
+	"log.scheduler.save_failed":      "Failed to save state of job %s: %v",
+	"log.leader.acquired":            "👑 This instance is now the leader and runs scheduled jobs",
+	"log.leader.follower":            "A leader is already elected, this instance stands by",
+	"log.leader.lost":                "⚠️ Leadership lost: %v",
+	"log.leader.released":            "Leadership released",
+	"log.leader.failed":              "Leader election failed: %v",
+	"log.prune.done":                 "🧹 Stale records deleted: %d",
+	"log.collect.started":            "📊 Collecting data from market.csgo.com...",
+	"log.collect.fetch_failed":       "Failed to fetch prices: %v",
+	"log.collect.received":           "Received %d items",
+	"log.collect.item_failed":        "Failed to create item %s: %v",
+	"log.collect.price_failed":       "Failed to add price for %s: %v",
+	"log.collect.done":               "✅ Processed %d items",
+	"log.collect.done_changes":       "✅ Prices stored: %d, unchanged: %d",
+	"log.collect.cache_loaded":       "Loaded last prices of %d items",
+	"log.collect.snapshot_failed":    "Failed to record price snapshot summary: %v",
+	"log.collect.storage_failed":     "Collection failed for a reason unrelated to the price source: %v",
+	"log.access.admins_failed":       "Failed to load admin list: %v",
+	"log.collect.retry":              "Price fetch attempt %d failed, retrying in %v: %v",
+	"log.collect.circuit_open":       "Source %s is paused by the circuit breaker until %s, skipping collection",
+	"log.collect.circuit_opened":     "Source %s paused after %d failed collections in a row: %v",
+	"log.collect.recovered":          "Source %s is available again",
+	"log.collect.interrupted":        "Collection interrupted, %d items stored",
+	"log.portfolio.record_failed":    "Failed to record trade: %v",
+	"log.portfolio.summary_failed":   "Failed to calculate portfolio: %v",
+	"log.portfolio.history_failed":   "Failed to load portfolio history: %v",
+	"log.search.failed":              "Search %q failed: %v",
+	"log.inline.search_failed":       "Inline search %q failed: %v",
+}
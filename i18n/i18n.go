@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+)
+
+// Язык по умолчанию для новых пользователей
+var Default = RU
+
+// Язык журналов приложения
+var logLang = RU
+
+var catalogs = map[Lang]map[string]string{
+	RU: ru,
+	EN: en,
+}
+
+// Поддерживаемые языки в порядке отображения
+func Supported() []Lang {
+	return []Lang{RU, EN}
+}
+
+// Разбор кода языка ("ru", "en", "en-US")
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	lang := Lang(code)
+	if _, ok := catalogs[lang]; ok {
+		return lang, true
+	}
+	return Default, false
+}
+
+// Перевод сообщения по ключу. Если ключа нет в выбранном языке,
+// используется русский каталог, а затем сам ключ.
+func T(lang Lang, key string, args ...interface{}) string {
+	tmpl, ok := catalogs[lang][key]
+	if !ok {
+		if tmpl, ok = catalogs[RU][key]; !ok {
+			tmpl = key
+		}
+	}
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
+
+// Форма слова для числа n. В каталоге формы перечисляются через "|":
+// для русского "предмет|предмета|предметов", для английского "item|items".
+func Plural(lang Lang, n int, key string) string {
+	forms := strings.Split(T(lang, key), "|")
+	idx := pluralIndex(lang, n)
+	if idx >= len(forms) {
+		idx = len(forms) - 1
+	}
+	return forms[idx]
+}
+
+// Число вместе с согласованным словом: "3 предмета", "1 item"
+func N(lang Lang, n int, key string) string {
+	return fmt.Sprintf("%d %s", n, Plural(lang, n, key))
+}
+
+func pluralIndex(lang Lang, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case RU:
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	default:
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+}
+
+func SetLogLanguage(lang Lang) {
+	logLang = lang
+}
+
+// Запись в журнал на языке журналов
+func Logf(key string, args ...interface{}) {
+	log.Print(T(logLang, key, args...))
+}
+
+// Фатальная ошибка с сообщением из каталога
+func Fatalf(key string, args ...interface{}) {
+	log.Fatal(T(logLang, key, args...))
+}
@@ -0,0 +1,430 @@
+package i18n
+
+var ru = map[string]string{
+	// Общие сообщения
+	"common.unknown_command": "Неизвестная команда. Используйте /start для помощи.",
+	"common.data_error":      "Ошибка получения данных. Попробуйте позже.",
+	"common.try_later":       "❌ Что-то пошло не так. Попробуйте позже.",
+
+	"welcome": `🎮 <b>Добро пожаловать в CS2 Skin Analyzer!</b>
+
+Этот бот поможет вам найти перспективные скины для инвестиций.
+
+📊 <b>Доступные команды:</b>
+/top - Топ перспективных скинов (с пагинацией)
+/budget - Рассчитать оптимальный портфель инвестиций
+/portfolio - Ваш портфель и P&amp;L
+/buy, /sell - Записать покупку или продажу
+/search - Найти предмет по названию (или просто напишите название)
+/subscribe - Ежедневный или еженедельный дайджест рынка
+/language - Сменить язык
+
+🚀 <b>Как это работает:</b>
+Бот анализирует ценовые тренды скинов и выдает рейтинг от 1 до 10, где 10 - максимально перспективный предмет для покупки.
+
+💡 <b>Рекомендации:</b>
+• 🟢 BUY - рекомендуется к покупке
+• 🟡 HOLD - держать если есть
+• 🔴 SELL - рекомендуется продать
+
+🔍 <b>Навигация:</b>
+• Нажмите на предмет для детального анализа
+• Используйте ⬅️➡️ для перехода между страницами`,
+
+	// Язык
+	"language.name":          "🇷🇺 Русский",
+	"language.choose":        "🌐 Выберите язык:",
+	"language.changed":       "✅ Язык изменен на русский",
+	"language.changed_group": "✅ Язык изменен на русский для личного чата с ботом и inline-режима. В группах бот отвечает на языке по умолчанию.",
+
+	// Единицы с формами множественного числа
+	"unit.point": "точка|точки|точек",
+	"unit.item":  "предмет|предмета|предметов",
+	"unit.lot":   "лот|лота|лотов",
+
+	// Категории
+	"category.knives":         "Ножи",
+	"category.weapons":        "Оружие",
+	"category.containers":     "Кейсы",
+	"category.keys":           "Ключи",
+	"category.packages":       "Пакеты",
+	"category.gloves":         "Перчатки",
+	"category.stickers":       "Стикеры",
+	"category.charms":         "Брелки",
+	"category.other":          "Прочее",
+	"category.knives.top":     "ТОП Ножей",
+	"category.weapons.top":    "ТОП Оружия",
+	"category.containers.top": "ТОП Кейсов",
+	"category.keys.top":       "ТОП Ключей",
+	"category.packages.top":   "ТОП Пакетов",
+	"category.gloves.top":     "ТОП Перчаток",
+	"category.stickers.top":   "ТОП Стикеров",
+	"category.charms.top":     "ТОП Брелков",
+	"category.all.top":        "ТОП Всех категорий",
+
+	// Топ предметов
+	"top.menu": "📂 <b>Выберите категорию для анализа:</b>\n\n" +
+		"🔪 Ножи - самые дорогие и стабильные инвестиции\n" +
+		"🔫 Оружие - популярные скины с хорошим потенциалом\n" +
+		"📦 Кейсы - контейнеры со скинами (только Case)\n" +
+		"🧤 Перчатки - редкие и ценные предметы\n" +
+		"🗝️ Ключи - для открытия кейсов\n" +
+		"📤 Пакеты - капсулы и сувениры\n" +
+		"🏷️ Стикеры - коллекционная ценность\n" +
+		"🎯 Брелки - новая категория предметов\n" +
+		"⭐ Все категории - общий топ",
+	"top.all_button": "⭐ Все категории",
+	"top.empty":      "В данной категории пока нет анализируемых предметов. Анализ обновляется автоматически, загляните позже.",
+	"top.page_title": "🏆 <b>%s</b> (стр. %d/%d)\n\n",
+	"top.item_line":  "   📊 Рейтинг: %d/10 | 💰 %.2f ₽ | 📈 %.1f%%\n",
+
+	// Навигация
+	"nav.prev":            "⬅️ Назад",
+	"nav.next":            "Вперед ➡️",
+	"nav.choose_category": "📂 Выбрать категорию",
+	"nav.back_to_list":    "⬅️ Назад к списку",
+
+	"callback.stale":     "Эта кнопка устарела. Откройте меню заново.",
+	"callback.malformed": "Не удалось обработать нажатие. Откройте меню заново.",
+
+	// Анализ
+	"analyze.started":  "🔄 Запускаю анализ рынка... Это может занять несколько минут.",
+	"analyze.failed":   "❌ Ошибка при анализе: %s",
+	"analyze.done":     "✅ Анализ завершен! Используйте /top для просмотра результатов.",
+	"analyze.joined":   "⏳ Анализ уже идет (%.0f%%), сообщу, когда он завершится.",
+	"analyze.progress": "🔄 Анализ рынка: %.0f%%",
+
+	// Карточка предмета
+	"item.error":          "Ошибка получения информации о предмете.",
+	"item.title":          "📊 <b>Подробный инвестиционный анализ</b>\n\n",
+	"item.category":       "📂 Категория: %s\n\n",
+	"item.price":          "💰 Цена: %.2f ₽\n",
+	"item.growth":         "📈 Рост: %.1f%% за период\n",
+	"item.volatility":     "📊 Волатильность: %.1f%%\n",
+	"item.score":          "⭐ Рейтинг: %d/10\n",
+	"item.risk":           "📉 Риск за %d дн.: волатильность %.0f%% годовых, VaR %.0f%% — %.1f%%, CVaR %.1f%%, макс. просадка %.1f%%, Sharpe %.2f, Sortino %.2f\n",
+	"item.recommendation": "%s Рекомендация: %s\n\n",
+	"item.why":            "🔍 <b>Почему стоит рассмотреть:</b>\n",
+	"item.strategy":       "\n📈 <b>Инвестиционная стратегия:</b>\n",
+	"item.not_analyzed":   "\n⚪ Предмет еще не проанализирован - рейтинг появится после следующего анализа.\n",
+	"item.data_points":    "\n📊 Надежность данных: %s\n",
+
+	// Советы
+	"advice.strong_buy": "Сильная покупка - отличный потенциал роста!",
+	"advice.cheap":      "Дешевый актив с хорошими перспективами",
+	"advice.premium":    "Стабильная дорогая инвестиция",
+	"advice.moderate":   "Умеренный потенциал, подходит для диверсификации",
+	"advice.risky":      "Рискованная инвестиция, требует анализа",
+
+	"analysis.score_high":          "✅ Высокий рейтинг указывает на сильные перспективы роста\n",
+	"analysis.score_medium":        "⚡ Средний рейтинг - предмет имеет потенциал\n",
+	"analysis.growth_fast":         "🚀 Демонстрирует активный рост цены\n",
+	"analysis.growth_steady":       "📈 Стабильный положительный тренд\n",
+	"analysis.price_low":           "💎 Низкая цена входа - минимальный риск\n",
+	"analysis.price_premium":       "💰 Premium сегмент - для серьезных инвесторов\n",
+	"analysis.category.knives":     "🔪 Ножи - самая стабильная категория для долгосрочных инвестиций\n",
+	"analysis.category.weapons":    "🔫 Оружие - высокая ликвидность и спрос\n",
+	"analysis.category.containers": "📦 Контейнеры - растут в цене со временем\n",
+	"analysis.category.gloves":     "🧤 Перчатки - редкая категория с ограниченным предложением\n",
+	"analysis.volatility_low":      "🛡️ Стабильная цена - низкий риск потерь\n",
+	"analysis.volatility_high":     "⚡ Высокая волатильность - возможны быстрые изменения\n",
+
+	"strategy.buy":                 "🟢 <b>Покупать сейчас</b> - оптимальная точка входа\n",
+	"strategy.buy_several":         "💡 Можно купить несколько штук для диверсификации\n",
+	"strategy.buy_hold":            "⏰ Рекомендуемый срок холда: 3-6 месяцев\n",
+	"strategy.hold":                "🟡 <b>Держать</b> - если уже есть в портфеле\n📊 Следить за динамикой, возможна покупка при снижении\n",
+	"strategy.sell":                "🔴 <b>Продавать</b> - высокий риск снижения\n💸 Рассмотреть фиксацию прибыли если есть\n",
+	"strategy.category.knives":     "🔪 Ножи лучше покупать в хорошем состоянии (MW, FN)\n",
+	"strategy.category.containers": "📦 Контейнеры - долгосрочная игра, держать минимум год\n",
+	"strategy.category.weapons":    "🔫 Популярное оружие (AK, M4, AWP) предпочтительнее\n",
+
+	// Калькулятор бюджета
+	"budget.intro": `💰 <b>Калькулятор бюджета</b>
+
+Рассчитаем оптимальный портфель для вашего капитала из предметов с чистой доходностью от <b>%+.0f%%</b> — после комиссии %s, вывода денег и проскальзывания при продаже.
+
+🎯 <b>Что делает калькулятор:</b>
+• Анализирует топ предметы с лучшими прогнозами
+• Оценивает доходность и риск по истории цен
+• Вычитает комиссии площадки и потери на продаже при малом объеме
+• Распределяет бюджет с учетом корреляций, ликвидности и выбранного уровня риска
+• Показывает ожидаемую прибыль и ее разброс
+
+💡 <b>Введите ваш бюджет в рублях:</b>
+Например: 10000`,
+	"budget.custom_button": "💬 Ввести свой",
+	"budget.use_command":   "💡 Чтобы рассчитать портфель на эту сумму, откройте калькулятор командой /budget и введите бюджет.",
+	"budget.custom_prompt": "💰 Введите ваш бюджет числом в рублях:\nНапример: 15000",
+	"budget.min":           "❌ Минимальный бюджет: 1000₽",
+	"budget.max":           "❌ Максимальный бюджет: 10,000,000₽",
+	"budget.error":         "❌ Ошибка при расчете портфеля. Попробуйте позже.",
+	"budget.nothing_found": "❌ Не найдено предметов с чистой доходностью от %+.0f%% после комиссий %s и проскальзывания",
+	"budget.title":         "💰 <b>Оптимальный портфель для %s₽</b>\n\n",
+	"budget.stats": "📊 <b>Общая статистика:</b>\n" +
+		"💵 К инвестированию: %s₽\n" +
+		"💰 Остаток: %s₽\n" +
+		"📈 Ожидаемая прибыль после издержек: %s₽\n" +
+		"🎯 Общий ROI: %+.1f%%\n",
+	"budget.costs":              "🧾 Продажа на %s: комиссия %.1f%%, вывод %.1f%%, плюс проскальзывание\n\n",
+	"budget.risk":               "⚖️ Риск: %s. Разброс прибыли за %d дн.: ±%s₽\n\n",
+	"budget.risk_low":           "низкий",
+	"budget.risk_medium":        "средний",
+	"budget.risk_high":          "высокий",
+	"budget.risk_button_low":    "🛡 Осторожнее",
+	"budget.risk_button_medium": "⚖️ Средний риск",
+	"budget.risk_button_high":   "🚀 Агрессивнее",
+	"budget.simulation": "🎲 <b>Моделирование на %d дн.:</b>\n" +
+		"   медиана: %s\n" +
+		"   плохой сценарий (5%%): %s\n" +
+		"   хороший сценарий (95%%): %s\n" +
+		"   вероятность убытка: %.0f%%\n\n",
+	"budget.simulation_caption": "🎲 %d сценариев стоимости портфеля на %d дн. по исторической динамике цен",
+	"budget.purchases":          "🛒 <b>Рекомендуемые покупки:</b>\n\n",
+	"budget.item_cost":          "   💸 %s₽ × %d шт = %s₽\n",
+	"budget.item_roi":           "   📈 ROI: %+.1f%% (+%s₽), рост цены %+.1f%%\n",
+	"budget.item_score":         "   ⭐ Рейтинг: %d/10\n\n",
+	"budget.disclaimer": "⚠️ <b>Важно:</b>\n" +
+		"• Это прогноз, реальная доходность может отличаться\n" +
+		"• Инвестируйте только те средства, которые готовы потерять\n" +
+		"• Рекомендуемый срок холда: 6-12 месяцев\n" +
+		"• Следите за обновлениями игры и рынка",
+	"budget.new_button": "🔄 Новый расчет",
+	"budget.top_button": "📊 Топ предметы",
+	"budget.again":      "💡 Хотите пересчитать с другим бюджетом или уровнем риска?",
+
+	// Портфель
+	"portfolio.entry_prompt": "✍️ Введите сделку в формате:\nНазвание; количество; цена; [площадка]; [дата ГГГГ-ММ-ДД]",
+	"portfolio.usage": `Формат записи сделки:
+/buy Название; количество; цена; [площадка]; [дата ГГГГ-ММ-ДД]
+/sell Название; количество; цена; [площадка]; [дата ГГГГ-ММ-ДД]
+
+Например:
+/buy AK-47 | Redline (Field-Tested); 2; 850; market.csgo.com; 2024-05-01`,
+	"portfolio.bad_quantity":   "❌ Количество должно быть целым положительным числом.",
+	"portfolio.bad_price":      "❌ Некорректная цена.",
+	"portfolio.bad_date":       "❌ Дата должна быть в формате ГГГГ-ММ-ДД.",
+	"portfolio.unknown_item":   "❌ Предмет не найден. Укажите точное название как на маркете.",
+	"portfolio.insufficient":   "❌ Нельзя продать больше, чем есть в портфеле.",
+	"portfolio.save_failed":    "❌ Не удалось сохранить сделку. Попробуйте позже.",
+	"portfolio.side.buy":       "Покупка",
+	"portfolio.side.sell":      "Продажа",
+	"portfolio.recorded":       "✅ %s записана\n\n%s\n%d шт × %.2f ₽ = %.2f ₽\n🏪 %s, %s\n\nСмотрите /portfolio",
+	"portfolio.error":          "❌ Ошибка расчета портфеля. Попробуйте позже.",
+	"portfolio.empty":          "💼 Портфель пуст.",
+	"portfolio.title":          "💼 Ваш портфель\n\n",
+	"portfolio.cost_basis":     "💵 Вложено (открытые позиции): %.2f ₽\n",
+	"portfolio.market_value":   "💰 Текущая стоимость: %.2f ₽\n",
+	"portfolio.unrealized":     "📈 Нереализованный P&L: %s\n",
+	"portfolio.realized":       "✅ Реализованный P&L: %+.2f ₽\n\n",
+	"portfolio.lots":           "🧾 Лоты:\n",
+	"portfolio.more_lots":      "… и еще %s\n",
+	"portfolio.lot_line":       "   %s, %s: %d/%d шт по %.2f ₽ → %.2f ₽\n",
+	"portfolio.lot_unrealized": "   Нереализ.: %s\n",
+	"portfolio.lot_realized":   "   Реализ.: %+.2f ₽\n",
+	"portfolio.allocation":     "\n📊 Распределение по категориям:\n",
+	"portfolio.chart_caption":  "📈 Стоимость портфеля за 30 дней",
+
+	// Поиск
+	"search.prompt":          "🔍 Введите название предмета:\nНапример: ak redline ft",
+	"search.expired":         "🔍 Поиск устарел, повторите запрос командой /search",
+	"search.error":           "Ошибка поиска. Попробуйте позже.",
+	"search.nothing":         "🔍 По запросу «%s» ничего не найдено.",
+	"search.title":           "🔍 Результаты поиска «%s»: %s (стр. %d/%d)\n\n",
+	"search.line":            "   %s Рейтинг: %d/10 | 💰 %.2f ₽ | 7д: %+.1f%%\n",
+	"search.line_unanalyzed": "   ⚪ Без анализа | 💰 %.2f ₽ | 7д: %+.1f%%\n",
+
+	// Inline-режим
+	"inline.open_bot":       "Открыть бота",
+	"inline.summary":        "💰 %.2f ₽ | 7д: %+.1f%%",
+	"inline.week_change":    "📈 За 7 дней: %+.1f%%\n",
+	"inline.recommendation": "%s Рекомендация: %s\n",
+	"inline.not_analyzed":   "⚪ Предмет еще не проанализирован\n",
+
+	// Графики
+	"chart.axis.date":         "Дата",
+	"chart.axis.price":        "Цена (₽)",
+	"chart.axis.value":        "Стоимость (₽)",
+	"chart.price.title":       "Динамика цены",
+	"chart.price.series":      "Цена",
+	"chart.price.trend":       "Тренд",
+	"chart.portfolio.title":   "Стоимость портфеля",
+	"chart.portfolio.series":  "Портфель",
+	"chart.axis.days":         "Дни",
+	"chart.simulation.title":  "Сценарии стоимости портфеля",
+	"chart.simulation.p95":    "95-й перцентиль",
+	"chart.simulation.p5":     "5-й перцентиль",
+	"chart.simulation.median": "Медиана",
+	"chart.simulation.cost":   "Вложено",
+
+	// Дайджест
+	"subscribe.usage": `Формат:
+/subscribe daily 09:00 [часовой пояс]
+/subscribe weekly пн 09:00 [часовой пояс]
+
+Часовой пояс по умолчанию — Europe/Moscow, например: Europe/Berlin, Asia/Almaty.`,
+	"subscribe.none":         "📰 Вы не подписаны на дайджест рынка.",
+	"subscribe.status":       "📰 Дайджест: %s.\nСледующий — %s.\n\nИзменить: /subscribe, отключить: /unsubscribe",
+	"subscribe.saved":        "✅ Подписка оформлена: %s.\nСледующий дайджест — %s.",
+	"subscribe.daily":        "ежедневно в %s (%s)",
+	"subscribe.weekly":       "еженедельно, %s в %s (%s)",
+	"subscribe.bad_time":     "❌ Укажите время в формате ЧЧ:ММ.",
+	"subscribe.bad_weekday":  "❌ Укажите день недели: пн, вт, ср, чт, пт, сб или вс.",
+	"subscribe.bad_timezone": "❌ Неизвестный часовой пояс.",
+	"unsubscribe.done":       "✅ Подписка на дайджест отключена.",
+	"weekday.0":              "воскресенье",
+	"weekday.1":              "понедельник",
+	"weekday.2":              "вторник",
+	"weekday.3":              "среда",
+	"weekday.4":              "четверг",
+	"weekday.5":              "пятница",
+	"weekday.6":              "суббота",
+	"digest.title.daily":     "📰 <b>Дайджест рынка за день</b> (%s)\n\n",
+	"digest.title.weekly":    "📰 <b>Дайджест рынка за неделю</b> (%s)\n\n",
+	"digest.movers":          "📊 <b>Лидеры роста и падения</b>\n",
+	"digest.no_movers":       "Заметных изменений цен не было.\n",
+	"digest.gainer":          "   📈 %s: %+.1f%% (%.2f ₽)\n",
+	"digest.loser":           "   📉 %s: %+.1f%% (%.2f ₽)\n",
+	"digest.new_buys":        "\n🟢 <b>Новые рекомендации BUY</b>\n",
+	"digest.no_new_buys":     "Новых рекомендаций нет.\n",
+	"digest.buy_line":        "   • %s — ⭐ %d/10, %.2f ₽\n",
+	"digest.portfolio":       "\n💼 <b>Портфель:</b> %.2f ₽\n",
+	"digest.portfolio_delta": "   С прошлого дайджеста: %+.2f ₽ (%+.1f%%)\n",
+	"digest.footer":          "\nОтписаться: /unsubscribe",
+
+	// Доступ и администрирование
+	"access.denied":       "⛔ Доступ к боту ограничен. Передайте администратору ваш ID: %d",
+	"access.denied_short": "⛔ Доступ ограничен",
+	"access.admin_only":   "⛔ Команда доступна только администраторам.",
+	"welcome.admin": `
+
+🛠 <b>Администрирование:</b>
+/analyze - Запустить анализ рынка
+/collect - Внеочередной сбор цен
+/stats - Статистика данных и сбора
+/users [роль] - Список пользователей
+/setrole ID|@имя роль - Назначить роль (admin, member, blocked)
+/reload - Перечитать параметры анализа и бюджета
+/jobs - Расписание и последние запуски задач`,
+	"stats.title":             "📊 <b>Статистика</b>\n\n",
+	"stats.rows":              "📦 Предметов: %d\n💾 Записей цен: %d\n🔍 Проанализировано: %d\n",
+	"stats.last_price":        "🕒 Последняя цена: %s\n",
+	"stats.last_analysis":     "🕒 Последний анализ: %s\n",
+	"stats.last_run":          "\n🛰 Последний сбор: %s (%s, %.0f с)\n   Получено: %d, записано: %d, ошибок записи: %d\n",
+	"stats.no_runs":           "\n🛰 Сбор еще не запускался\n",
+	"stats.run_ok":            "успешно",
+	"stats.run_failed":        "ошибка",
+	"reload.done":             "🔄 Параметры перезагружены:\n",
+	"reload.failed":           "❌ Параметры не перезагружены, действуют прежние:\n",
+	"alert.collect_failing":   "⚠️ Сбор цен с %s не удается %d раз подряд (%s): %s\nИсточник отключен, следующая попытка в %s.",
+	"alert.collect_auth":      "🔑 %s отклоняет API-ключ: %s\nСбор остановлен, следующая попытка в %s. Проверьте MARKET_API_KEY.",
+	"alert.collect_recovered": "✅ Сбор цен с %s восстановлен.",
+	"stats.failed_runs":       "❗ Неудачных сборов за сутки: %d\n",
+	"stats.last_error":        "❗ Последняя ошибка (%s): %s\n",
+	"stats.users":             "\n👥 Пользователи: администраторов %d, участников %d, заблокированных %d\n",
+	"stats.never":             "—",
+	"jobs.title":              "🗓 <b>Задачи по расписанию</b>\n",
+	"jobs.empty":              "🗓 Расписание еще не сохранено.",
+	"jobs.item":               "\n<b>%s</b> <code>%s</code>\n   Следующий запуск: %s\n",
+	"jobs.running":            "   ⏳ Выполняется: %.0f%%\n",
+	"jobs.never_run":          "   Еще не запускалась\n",
+	"jobs.not_leader":         "⚠️ Задачи выполняет экземпляр-лидер, а этот экземпляр им сейчас не является. Повторите команду позже или отправьте ее лидеру.",
+	"jobs.last_ok":            "   ✅ Последний запуск: %s (%v)\n",
+	"jobs.last_failed":        "   ❌ Последний запуск: %s (%v): %s\n",
+	"collect.started":         "🛰 Запускаю сбор цен...",
+	"collect.done":            "✅ Сбор завершен: получено %d, записано %d, ошибок записи %d.",
+	"collect.failed":          "❌ Ошибка сбора: %s",
+	"collect.joined":          "⏳ Сбор цен уже идет (%.0f%%), сообщу, когда он завершится.",
+	"collect.progress":        "🛰 Сбор цен: %.0f%%",
+	"collect.done_short":      "✅ Сбор завершен.",
+	"users.title":             "👥 Пользователи:\n\n",
+	"users.line":              "%d %s — %s (с %s)\n",
+	"users.hint":              "\nИзменить роль: /setrole ID роль",
+	"users.empty":             "Пользователей не найдено.",
+	"users.bad_role":          "❌ Роль должна быть одной из: admin, member, blocked.",
+	"setrole.usage":           "Формат: /setrole ID|@имя admin|member|blocked",
+	"setrole.unknown_user":    "❌ Пользователь не найден. Укажите числовой ID.",
+	"setrole.config_admin":    "❌ Роль этого администратора задана в конфигурации (ADMIN_IDS).",
+	"setrole.done":            "✅ Пользователю %d назначена роль %s.",
+
+	// Журналы
+	"log.config.invalid":             "Некорректная конфигурация:\n%v",
+	"log.config.loaded":              "Конфигурация:\n%s",
+	"log.db.connect_failed":          "Ошибка подключения к базе данных: %v",
+	"log.db.migrate_failed":          "Ошибка миграции базы данных: %v",
+	"log.digest.load_failed":         "Ошибка загрузки данных дайджеста: %v",
+	"log.digest.save_failed":         "Ошибка сохранения подписки %d: %v",
+	"log.digest.send_failed":         "Ошибка отправки дайджеста в чат %d: %v",
+	"log.digest.sent":                "Дайджесты отправлены: %d из %d",
+	"log.send.retry":                 "Повтор отправки в чат %d (попытка %d, через %v): %v",
+	"log.send.failed":                "Не удалось отправить сообщение в чат %d: %v",
+	"log.send.blocked":               "Чат %d недоступен, отправка прекращена: %s",
+	"log.send.unblocked":             "Чат %d снова доступен",
+	"log.send.block_save_failed":     "Ошибка сохранения статуса чата %d: %v",
+	"log.send.blocked_load_failed":   "Ошибка загрузки недоступных чатов: %v",
+	"log.access.load_failed":         "Ошибка чтения роли пользователя %d: %v",
+	"log.access.save_failed":         "Ошибка сохранения пользователя %d: %v",
+	"log.access.stats_failed":        "Ошибка получения статистики: %v",
+	"log.access.role_changed":        "Пользователю %d назначена роль %s (администратор %d)",
+	"log.collect.record_failed":      "Ошибка записи журнала сбора: %v",
+	"log.params.reloaded":            "Параметры анализа и бюджета перезагружены (%v)",
+	"log.params.reload_failed":       "Ошибка перезагрузки параметров: %v",
+	"log.params.active":              "Действующие параметры:\n%s",
+	"log.analysis.record_failed":     "Ошибка записи журнала анализа: %v",
+	"log.bot.create_failed":          "Ошибка создания бота: %v",
+	"log.bot.authorized":             "Бот авторизован как %s",
+	"log.bot.started":                "🤖 Бот запущен и готов к работе!",
+	"log.bot.language_save_failed":   "Ошибка сохранения языка для %d: %v",
+	"log.bot.webhook_registered":     "Вебхук зарегистрирован: %s",
+	"log.bot.webhook_bad_update":     "Некорректный апдейт в вебхуке: %v",
+	"log.bot.webhook_failed":         "Ошибка регистрации вебхука: %v",
+	"log.bot.callback_encode_failed": "Не удалось закодировать callback %s: %v",
+	"log.bot.state_save_failed":      "Ошибка сохранения состояния диалога для %d: %v",
+	"log.bot.state_load_failed":      "Ошибка чтения состояния диалога для %d: %v",
+	"log.bot.callback_malformed":     "Некорректный callback %q: %v",
+	"log.http.listening":             "HTTP-сервер слушает порт %s",
+	"log.api.failed":                 "Ошибка запроса API %s: %v",
+	"log.budget.failed":              "Ошибка расчета портфеля для чата %d: %v",
+	"log.budget.chart_failed":        "Ошибка построения графика моделирования для чата %d: %v",
+	"log.http.failed":                "Ошибка HTTP-сервера: %v",
+	"log.http.shutdown_failed":       "Ошибка остановки HTTP-сервера: %v",
+	"log.bot.stopped":                "Обработка апдейтов остановлена",
+	"log.shutdown.started":           "Получен сигнал завершения, останавливаюсь...",
+	"log.shutdown.done":              "Работа завершена",
+	"log.shutdown.timeout":           "Не удалось корректно завершиться за %v, выход",
+	"log.jobs.started":               "▶️ Задача %s запущена",
+	"log.jobs.finished":              "✅ Задача %s завершена за %v",
+	"log.jobs.failed":                "❌ Задача %s завершилась с ошибкой через %v: %v",
+	"log.scheduler.scheduled":        "🗓 Задача %s по расписанию %q, следующий запуск %s",
+	"log.scheduler.skipped":          "⏭ Задача %s еще выполняется, запуск по расписанию пропущен",
+	"log.scheduler.idle":             "Нет задач со следующим запуском, планировщик ожидает остановки",
+	"log.scheduler.invalid":          "Некорректное расписание задачи %s: %v",
+	"log.scheduler.state_failed":     "Ошибка загрузки состояния задач: %v",
+	"log.scheduler.save_failed":      "Ошибка сохранения состояния задачи %s: %v",
+	"log.leader.acquired":            "👑 Экземпляр стал лидером и выполняет задачи по расписанию",
+	"log.leader.follower":            "Лидер уже выбран, экземпляр ожидает в резерве",
+	"log.leader.lost":                "⚠️ Лидерство потеряно: %v",
+	"log.leader.released":            "Лидерство передано",
+	"log.leader.failed":              "Ошибка выбора лидера: %v",
+	"log.prune.done":                 "🧹 Удалено устаревших записей: %d",
+	"log.collect.started":            "📊 Собираю данные с market.csgo.com...",
+	"log.collect.fetch_failed":       "Ошибка получения цен: %v",
+	"log.collect.received":           "Получено %d предметов",
+	"log.collect.item_failed":        "Ошибка создания предмета %s: %v",
+	"log.collect.price_failed":       "Ошибка добавления цены для %s: %v",
+	"log.collect.done":               "✅ Обработано %d предметов",
+	"log.collect.done_changes":       "✅ Записано цен: %d, без изменений: %d",
+	"log.collect.cache_loaded":       "Загружены последние цены %d предметов",
+	"log.collect.snapshot_failed":    "Ошибка записи итогов снимка цен: %v",
+	"log.collect.storage_failed":     "Сбор не завершен из-за ошибки, не связанной с источником цен: %v",
+	"log.access.admins_failed":       "Ошибка чтения списка администраторов: %v",
+	"log.collect.retry":              "Попытка %d получить цены не удалась, повтор через %v: %v",
+	"log.collect.circuit_open":       "Источник %s отключен автоматом защиты до %s, сбор пропущен",
+	"log.collect.circuit_opened":     "Источник %s отключен после %d неудачных сборов подряд: %v",
+	"log.collect.recovered":          "Источник %s снова доступен",
+	"log.collect.interrupted":        "Сбор прерван, записано %d предметов",
+	"log.portfolio.record_failed":    "Ошибка записи сделки: %v",
+	"log.portfolio.summary_failed":   "Ошибка расчета портфеля: %v",
+	"log.portfolio.history_failed":   "Ошибка истории портфеля: %v",
+	"log.search.failed":              "Ошибка поиска %q: %v",
+	"log.inline.search_failed":       "Ошибка inline-поиска %q: %v",
+}
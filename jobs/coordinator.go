@@ -0,0 +1,248 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"buff-youpin-checker/i18n"
+)
+
+// Имена задач
+const (
+	Analysis   = "analysis"
+	Collection = "collection"
+	Candles    = "candles"
+	Prune      = "prune"
+	Digests    = "digests"
+)
+
+// Функция задачи; progress сообщает, сколько из total шагов выполнено.
+// Задача должна завершиться при отмене ctx.
+type Func func(ctx context.Context, progress func(done, total int)) error
+
+var (
+	// Координатор остановлен, новые задачи не запускаются
+	ErrStopped = errors.New("job coordinator is stopped")
+	// Задачи выполняет только лидер, а экземпляр им не является
+	ErrNotLeader = errors.New("this instance is not the leader")
+)
+
+// Запуск задачи
+type Run struct {
+	Name      string
+	StartedAt time.Time
+
+	done     chan struct{}
+	err      error
+	finished time.Time
+	progress atomic.Int64 // доля выполнения в десятых долях процента
+}
+
+// Канал закрывается по завершении задачи
+func (r *Run) Done() <-chan struct{} {
+	return r.done
+}
+
+// Ошибка завершившейся задачи
+func (r *Run) Err() error {
+	<-r.done
+	return r.err
+}
+
+// Время выполнения завершившейся задачи
+func (r *Run) Duration() time.Duration {
+	<-r.done
+	return r.finished.Sub(r.StartedAt)
+}
+
+// Процент выполнения от 0 до 100
+func (r *Run) Progress() float64 {
+	return float64(r.progress.Load()) / 10
+}
+
+func (r *Run) setProgress(done, total int) {
+	if total <= 0 {
+		return
+	}
+	if done > total {
+		done = total
+	}
+	r.progress.Store(int64(done) * 1000 / int64(total))
+}
+
+// Координатор гарантирует, что каждая задача выполняется не более чем
+// в одном экземпляре: повторный запуск присоединяется к текущему.
+// После RequireLeader задачи запускаются только внутри Lead.
+type Coordinator struct {
+	ctx      context.Context
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	funcs    map[string]Func
+	quiet    map[string]bool
+	running  map[string]*Run
+	onFinish func(run *Run)
+
+	requireLeader bool
+	leadCtx       context.Context // nil, пока экземпляр не лидер
+	leadRuns      sync.WaitGroup  // запуски, начатые при текущем лидерстве
+}
+
+// Задачи выполняются с контекстом ctx: его отмена останавливает
+// текущие запуски и запрещает новые
+func NewCoordinator(ctx context.Context) *Coordinator {
+	return &Coordinator{
+		ctx:     ctx,
+		funcs:   make(map[string]Func),
+		quiet:   make(map[string]bool),
+		running: make(map[string]*Run),
+	}
+}
+
+func (c *Coordinator) Register(name string, fn Func) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.funcs[name] = fn
+}
+
+// Как Register, но успешные запуски не пишутся в журнал (для частых задач)
+func (c *Coordinator) RegisterQuiet(name string, fn Func) {
+	c.Register(name, fn)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.quiet[name] = true
+}
+
+// Запуск задач, в том числе ручных, только пока экземпляр лидер
+func (c *Coordinator) RequireLeader() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requireLeader = true
+}
+
+// Выполнение lead на время лидерства. Задачи, запущенные до отмены ctx,
+// выполняются с этим контекстом: при потере лидерства они отменяются,
+// и Lead возвращается только после их завершения, поэтому новый лидер
+// не запустит ту же задачу параллельно.
+func (c *Coordinator) Lead(ctx context.Context, lead func(ctx context.Context)) {
+	c.mu.Lock()
+	c.leadCtx = ctx
+	c.mu.Unlock()
+
+	lead(ctx)
+	<-ctx.Done()
+
+	c.mu.Lock()
+	c.leadCtx = nil
+	c.mu.Unlock()
+	c.leadRuns.Wait()
+}
+
+// Зарегистрирована ли задача
+func (c *Coordinator) Has(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.funcs[name]
+	return ok
+}
+
+// Обработчик завершения любого запуска, ручного или по расписанию
+func (c *Coordinator) OnFinish(fn func(run *Run)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onFinish = fn
+}
+
+// Запуск задачи. Если она уже выполняется, возвращается текущий запуск
+// и started == false.
+func (c *Coordinator) Trigger(name string) (run *Run, started bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if run, ok := c.running[name]; ok {
+		return run, false, nil
+	}
+	if c.ctx.Err() != nil {
+		return nil, false, ErrStopped
+	}
+	if c.requireLeader && (c.leadCtx == nil || c.leadCtx.Err() != nil) {
+		return nil, false, ErrNotLeader
+	}
+
+	fn, ok := c.funcs[name]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown job %q", name)
+	}
+
+	run = &Run{Name: name, StartedAt: time.Now(), done: make(chan struct{})}
+	c.running[name] = run
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	release := func() { cancel() }
+	if c.leadCtx != nil {
+		stop := context.AfterFunc(c.leadCtx, cancel)
+		c.leadRuns.Add(1)
+		release = func() {
+			stop()
+			cancel()
+			c.leadRuns.Done()
+		}
+	}
+
+	c.wg.Add(1)
+	go c.execute(ctx, release, run, fn)
+	return run, true, nil
+}
+
+// Ожидание завершения всех запущенных задач
+func (c *Coordinator) Wait() {
+	c.wg.Wait()
+}
+
+// Текущий запуск задачи или nil
+func (c *Coordinator) Current(name string) *Run {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.running[name]
+}
+
+func (c *Coordinator) execute(ctx context.Context, release func(), run *Run, fn Func) {
+	defer c.wg.Done()
+	defer release()
+
+	c.mu.Lock()
+	quiet := c.quiet[run.Name]
+	c.mu.Unlock()
+
+	if !quiet {
+		i18n.Logf("log.jobs.started", run.Name)
+	}
+
+	err := fn(ctx, run.setProgress)
+
+	c.mu.Lock()
+	delete(c.running, run.Name)
+	onFinish := c.onFinish
+	c.mu.Unlock()
+
+	run.err = err
+	run.finished = time.Now()
+	if err == nil {
+		run.progress.Store(1000)
+	}
+	close(run.done)
+
+	if err != nil {
+		i18n.Logf("log.jobs.failed", run.Name, run.finished.Sub(run.StartedAt).Round(time.Second), err)
+	} else if !quiet {
+		i18n.Logf("log.jobs.finished", run.Name, run.finished.Sub(run.StartedAt).Round(time.Second))
+	}
+
+	if onFinish != nil {
+		onFinish(run)
+	}
+}
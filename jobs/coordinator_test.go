@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoordinatorLead(t *testing.T) {
+	c := NewCoordinator(context.Background())
+	c.RequireLeader()
+
+	stopped := make(chan struct{})
+	c.Register(Collection, func(ctx context.Context, progress func(done, total int)) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+
+	if _, _, err := c.Trigger(Collection); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("до избрания: %v, ожидалась ErrNotLeader", err)
+	}
+
+	leadCtx, lose := context.WithCancel(context.Background())
+	returned := make(chan struct{})
+	started := make(chan *Run, 1)
+	go func() {
+		c.Lead(leadCtx, func(ctx context.Context) {
+			run, _, err := c.Trigger(Collection)
+			if err != nil {
+				t.Errorf("лидер: %v", err)
+			}
+			started <- run
+		})
+		close(returned)
+	}()
+
+	run := <-started
+	lose()
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Lead не вернулся после потери лидерства")
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Lead вернулся раньше, чем остановилась задача")
+	}
+	if !errors.Is(run.Err(), context.Canceled) {
+		t.Errorf("ошибка задачи %v, ожидалась отмена", run.Err())
+	}
+
+	if _, _, err := c.Trigger(Collection); !errors.Is(err, ErrNotLeader) {
+		t.Errorf("после потери лидерства: %v, ожидалась ErrNotLeader", err)
+	}
+}
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/metrics"
+)
+
+// Выбор лидера между экземплярами через рекомендательную блокировку
+// Postgres. Задачи по расписанию выполняет только лидер; если он
+// завершился или потерял соединение с базой, блокировку за интервал
+// проверки подхватывает другой экземпляр.
+type Elector struct {
+	db       *database.DB
+	interval time.Duration
+	leader   atomic.Bool
+}
+
+func NewElector(db *database.DB, interval time.Duration) *Elector {
+	return &Elector{db: db, interval: interval}
+}
+
+// Является ли экземпляр лидером
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Пока экземпляр лидер, выполняет lead; его контекст отменяется при потере
+// лидерства. Завершается с отменой ctx.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	announced := false
+	for {
+		lock, err := e.db.TryLeaderLock(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				i18n.Logf("log.leader.failed", err)
+			}
+		case lock == nil:
+			if !announced {
+				i18n.Logf("log.leader.follower")
+				announced = true
+			}
+		default:
+			e.hold(ctx, lock, lead)
+			announced = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Выполнение lead, пока блокировка за экземпляром
+func (e *Elector) hold(ctx context.Context, lock *database.LeaderLock, lead func(ctx context.Context)) {
+	e.leader.Store(true)
+	metrics.Leader.Set(1)
+	i18n.Logf("log.leader.acquired")
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		lead(leadCtx)
+	}()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			if err := lock.Check(ctx); err != nil {
+				if ctx.Err() == nil {
+					i18n.Logf("log.leader.lost", err)
+				}
+				break loop
+			}
+		}
+	}
+
+	// lead завершается после остановки запущенных им задач, поэтому
+	// блокировка освобождается, когда задачи уже не выполняются
+	cancel()
+	wg.Wait()
+
+	e.leader.Store(false)
+	metrics.Leader.Set(0)
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelRelease()
+	if err := lock.Release(releaseCtx); err == nil && ctx.Err() != nil {
+		i18n.Logf("log.leader.released")
+	}
+}
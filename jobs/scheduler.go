@@ -0,0 +1,148 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"buff-youpin-checker/config"
+	"buff-youpin-checker/cron"
+	"buff-youpin-checker/database"
+	"buff-youpin-checker/i18n"
+)
+
+// Запуск задач координатора по расписанию cron. Время следующего запуска
+// хранится в базе, поэтому пропущенные за время простоя запуски видны
+// после перезапуска.
+type Scheduler struct {
+	coordinator *Coordinator
+	db          *database.DB
+	loc         *time.Location
+	entries     []*entry
+}
+
+type entry struct {
+	name     string
+	schedule *cron.Schedule
+	catchUp  string
+	next     time.Time
+}
+
+func NewScheduler(coordinator *Coordinator, db *database.DB, loc *time.Location) *Scheduler {
+	s := &Scheduler{coordinator: coordinator, db: db, loc: loc}
+
+	// Результаты всех запусков, в том числе ручных, сохраняются в базе
+	coordinator.OnFinish(func(run *Run) {
+		errText := ""
+		if run.Err() != nil {
+			errText = run.Err().Error()
+		}
+		if err := db.SaveJobRun(run.Name, run.StartedAt, run.StartedAt.Add(run.Duration()), errText); err != nil {
+			i18n.Logf("log.scheduler.save_failed", run.Name, err)
+		}
+	})
+	return s
+}
+
+// Добавление задачи в расписание; задача должна быть зарегистрирована в координаторе
+func (s *Scheduler) Add(name string, job config.JobSchedule) error {
+	if !s.coordinator.Has(name) {
+		return fmt.Errorf("job %q is not registered", name)
+	}
+	schedule, err := cron.Parse(job.Cron)
+	if err != nil {
+		return err
+	}
+	if schedule.Next(time.Now().In(s.loc)).IsZero() {
+		return fmt.Errorf("job %q: schedule %q never fires", name, schedule)
+	}
+	catchUp := job.CatchUp
+	if catchUp == "" {
+		catchUp = config.CatchUpRunOnce
+	}
+	s.entries = append(s.entries, &entry{name: name, schedule: schedule, catchUp: catchUp})
+	return nil
+}
+
+// Основной цикл; завершается с отменой ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.entries) == 0 {
+		return
+	}
+
+	states, err := s.db.GetJobStates()
+	if err != nil {
+		i18n.Logf("log.scheduler.state_failed", err)
+	}
+
+	now := time.Now().In(s.loc)
+	var due []*entry
+	for _, e := range s.entries {
+		e.next = e.schedule.Next(now)
+
+		// Запуск пропущен, если задача новая или срок прошел во время простоя.
+		// При смене расписания прежний срок не учитывается.
+		st, known := states[e.name]
+		missed := !known || st.Schedule != e.schedule.String() || st.NextRunAt.Before(now)
+		if missed && e.catchUp == config.CatchUpRunOnce {
+			due = append(due, e)
+		}
+		s.save(e)
+		i18n.Logf("log.scheduler.scheduled", e.name, e.schedule, e.next.Format("2006-01-02 15:04 MST"))
+	}
+
+	for _, e := range due {
+		s.trigger(e)
+	}
+
+	for {
+		// Задачи без следующего срабатывания не ждем: иначе таймер на
+		// нулевое время срабатывал бы непрерывно
+		var earliest *entry
+		for _, e := range s.entries {
+			if !e.next.IsZero() && (earliest == nil || e.next.Before(earliest.next)) {
+				earliest = e
+			}
+		}
+		if earliest == nil {
+			i18n.Logf("log.scheduler.idle")
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(earliest.next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now().In(s.loc)
+		for _, e := range s.entries {
+			if e.next.IsZero() || e.next.After(now) {
+				continue
+			}
+			e.next = e.schedule.Next(now)
+			s.save(e)
+			s.trigger(e)
+		}
+	}
+}
+
+func (s *Scheduler) trigger(e *entry) {
+	_, started, err := s.coordinator.Trigger(e.name)
+	if err != nil {
+		return
+	}
+	// Предыдущий запуск (ручной или по расписанию) еще идет — второй не нужен
+	if !started {
+		i18n.Logf("log.scheduler.skipped", e.name)
+	}
+}
+
+func (s *Scheduler) save(e *entry) {
+	if err := s.db.SaveJobSchedule(e.name, e.schedule.String(), e.next); err != nil {
+		i18n.Logf("log.scheduler.save_failed", e.name, err)
+	}
+}
@@ -1,151 +1,224 @@
 package main
 
 import (
-	"log"
-	"strconv"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"buff-youpin-checker/analyzer"
+	"buff-youpin-checker/api"
 	"buff-youpin-checker/bot"
+	"buff-youpin-checker/collector"
 	"buff-youpin-checker/config"
 	"buff-youpin-checker/database"
+	"buff-youpin-checker/health"
+	"buff-youpin-checker/i18n"
+	"buff-youpin-checker/jobs"
 	"buff-youpin-checker/market"
+	"buff-youpin-checker/metrics"
 )
 
 func main() {
-	// Загружаем конфигурацию
-	cfg := config.Load()
-	
-	// Отладочная информация
-	log.Printf("Подключение к БД: host=%s port=%s user=%s password=%s dbname=%s", 
-		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName)
-	
+	// Корневой контекст отменяется по SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Загружаем конфигурацию; некорректные значения — ошибка запуска
+	cfg, err := config.Load()
+	if err != nil {
+		i18n.Fatalf("log.config.invalid", err)
+	}
+
+	// Язык интерфейса по умолчанию и язык журналов
+	if lang, ok := i18n.Parse(cfg.Locale.Language); ok {
+		i18n.Default = lang
+	}
+	if lang, ok := i18n.Parse(cfg.Locale.LogLanguage); ok {
+		i18n.SetLogLanguage(lang)
+	}
+
+	// Секреты в String() скрыты
+	i18n.Logf("log.config.loaded", cfg)
+
 	// Подключаемся к базе данных
 	db, err := database.Connect(cfg)
 	if err != nil {
-		log.Fatal("Ошибка подключения к базе данных:", err)
+		i18n.Fatalf("log.db.connect_failed", err)
 	}
 	defer db.Close()
 
+	if err := db.Migrate(); err != nil {
+		i18n.Fatalf("log.db.migrate_failed", err)
+	}
+
 	// Создаем клиент для Market API
-	marketClient := market.NewClient(cfg.MarketAPIKey)
+	marketClient := market.NewClient(cfg.Market)
+
+	// Сборщик цен
+	dataCollector := collector.New(marketClient, db, cfg.Market)
 
 	// Создаем анализатор трендов
-	trendAnalyzer := analyzer.NewTrendAnalyzer(db)
+	// Параметры анализа и бюджета перечитываются по SIGHUP и команде /reload
+	params := config.NewParamsStore(cfg)
+	trendAnalyzer := analyzer.NewTrendAnalyzer(db, params)
+
+	// Координатор не дает анализу и сбору выполняться в нескольких экземплярах
+	coordinator := jobs.NewCoordinator(ctx)
+	coordinator.Register(jobs.Collection, func(ctx context.Context, progress func(done, total int)) error {
+		_, err := dataCollector.Run(ctx, progress)
+		return err
+	})
+	coordinator.Register(jobs.Analysis, trendAnalyzer.AnalyzeAllItems)
+
+	// Обслуживание базы: свечи за последние двое суток и удаление устаревших записей
+	coordinator.Register(jobs.Candles, func(ctx context.Context, progress func(done, total int)) error {
+		_, err := db.AggregateCandles(ctx, 2)
+		return err
+	})
+	coordinator.Register(jobs.Prune, func(ctx context.Context, progress func(done, total int)) error {
+		deleted, err := db.Prune(ctx, cfg.Schedule.Retention)
+		if err == nil {
+			i18n.Logf("log.prune.done", deleted)
+		}
+		return err
+	})
 
 	// Создаем бота
-	telegramBot, err := bot.NewBot(cfg.TelegramToken, trendAnalyzer, db)
+	access := bot.AccessPolicy{
+		Allowlist: cfg.Access.Mode == "allowlist",
+		Admins:    cfg.Access.Admins,
+	}
+	telegramBot, err := bot.NewBot(cfg.Telegram.Token, trendAnalyzer, coordinator, db, access, params)
 	if err != nil {
-		log.Fatal("Ошибка создания бота:", err)
+		i18n.Fatalf("log.bot.create_failed", err)
 	}
 
-	// Запускаем сбор данных в отдельной горутине
-	go startDataCollection(marketClient, db)
+	// Постоянные сбои источника цен сообщаются администраторам
+	dataCollector.OnAlert(telegramBot.AlertAdmins)
 
-	// Запускаем анализ в отдельной горутине
-	go startPeriodicAnalysis(trendAnalyzer)
+	// Рассылка дайджестов проверяется каждую минуту, поэтому не засоряет журнал
+	coordinator.RegisterQuiet(jobs.Digests, telegramBot.SendDueDigests)
 
-	// Мгновенный первый анализ при старте
-	go func() {
-		log.Println("🔍 Выполняю первичный анализ при старте...")
-		if err := trendAnalyzer.AnalyzeAllItems(); err != nil {
-			log.Printf("Ошибка первичного анализа: %v", err)
-		} else {
-			log.Println("✅ Первичный анализ завершен")
-		}
-	}()
-
-	log.Println("🤖 Бот запущен и готов к работе!")
-	
-	// Запускаем бота (блокирующий вызов)
-	telegramBot.Start()
-}
-
-// Периодический сбор данных с market.csgo.com
-func startDataCollection(client *market.Client, db *database.DB) {
-	ticker := time.NewTicker(10 * time.Minute) // Каждые 10 минут
-	defer ticker.Stop()
-
-	for {
-		log.Println("📊 Собираю данные с market.csgo.com...")
-		
-		// Получаем текущие цены
-		priceResponse, err := client.GetPrices()
-		if err != nil {
-			log.Printf("Ошибка получения цен: %v", err)
-			<-ticker.C
+	// Расписание задач; часовой пояс уже проверен при загрузке конфигурации
+	loc, _ := time.LoadLocation(cfg.Schedule.Timezone)
+	scheduler := jobs.NewScheduler(coordinator, db, loc)
+	for name, job := range cfg.Schedule.Jobs {
+		if job.Disabled {
 			continue
 		}
-
-		if !priceResponse.Success {
-			log.Println("API вернул ошибку в ответе")
-			<-ticker.C
-			continue
+		if err := scheduler.Add(name, job); err != nil {
+			i18n.Fatalf("log.scheduler.invalid", name, err)
 		}
+	}
 
-		log.Printf("Получено %d предметов", len(priceResponse.Items))
+	var background sync.WaitGroup
+	runBackground := func(fn func()) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			fn()
+		}()
+	}
 
-		// Обрабатываем данные
-		processedCount := 0
-		for _, item := range priceResponse.Items {
-			price := parseFloat(item.Price)
-			if price <= 0 {
-				continue
-			}
+	// Сбор, анализ, обслуживание базы и дайджесты по расписанию. При
+	// нескольких экземплярах расписание выполняет только лидер, а бот и
+	// HTTP обслуживают все. Ручные /collect и /analyze в этом режиме тоже
+	// выполняет только лидер.
+	if cfg.Schedule.LeaderElection {
+		coordinator.RequireLeader()
+		elector := jobs.NewElector(db, cfg.Schedule.LeaderCheckInterval)
+		runBackground(func() {
+			elector.Run(ctx, func(leadCtx context.Context) {
+				coordinator.Lead(leadCtx, scheduler.Run)
+			})
+		})
+	} else {
+		runBackground(func() { scheduler.Run(ctx) })
+	}
 
-			// Создаем или обновляем предмет
-			dbItem := &database.Item{
-				HashName:   item.MarketHashName,
-				MarketName: item.MarketHashName,
-				ClassID:    "unknown",
-				InstanceID: "0",
-			}
+	runBackground(func() { reloadOnSignal(ctx, params) })
+
+	// Метрики и проверки состояния доступны в любом режиме
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", health.Liveness(db))
+	mux.Handle("/readyz", health.Readiness(db))
+	mux.Handle("/marketz", health.MarketFreshness(db, cfg.Server.MaxDataAge))
+	mux.Handle("/api/", api.Handler(db, cfg.Server.APIToken))
+
+	webhook := cfg.Telegram.Mode == "webhook"
+	if webhook {
+		tg := cfg.Telegram
+		if err := telegramBot.StartWebhook(mux, tg.WebhookURL, tg.WebhookPath, tg.WebhookSecret); err != nil {
+			i18n.Fatalf("log.bot.webhook_failed", err)
+		}
+	}
 
-			err = db.CreateItem(dbItem)
-			if err != nil {
-				log.Printf("Ошибка создания предмета %s: %v", item.MarketHashName, err)
-				continue
-			}
+	server := &http.Server{Addr: ":" + cfg.Server.Port, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			i18n.Fatalf("log.http.failed", err)
+		}
+	}()
+	i18n.Logf("log.http.listening", cfg.Server.Port)
+	i18n.Logf("log.bot.started")
+
+	if webhook {
+		// Апдейты приходят через HTTP-сервер
+		<-ctx.Done()
+	} else {
+		// Запускаем бота (блокирующий вызов до сигнала остановки)
+		telegramBot.Start(ctx)
+	}
 
-			// Добавляем цену в историю
-			err = db.AddPriceHistory(dbItem.ID, price, "RUB", "market.csgo.com")
-			if err != nil {
-				log.Printf("Ошибка добавления цены для %s: %v", item.MarketHashName, err)
-				continue
-			}
+	// Повторный сигнал завершает процесс сразу
+	stop()
+	i18n.Logf("log.shutdown.started")
 
-			processedCount++
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			i18n.Logf("log.http.shutdown_failed", err)
 		}
+		telegramBot.Shutdown()
+		background.Wait()
+		coordinator.Wait()
+	}()
 
-		log.Printf("✅ Обработано %d предметов", processedCount)
-		<-ticker.C
+	select {
+	case <-done:
+		i18n.Logf("log.shutdown.done")
+	case <-time.After(cfg.Server.ShutdownTimeout):
+		i18n.Fatalf("log.shutdown.timeout", cfg.Server.ShutdownTimeout)
 	}
 }
 
-// Периодический анализ трендов
-func startPeriodicAnalysis(analyzer *analyzer.TrendAnalyzer) {
-	ticker := time.NewTicker(30 * time.Minute) // Каждые 30 минут
-	defer ticker.Stop()
+// Перезагрузка параметров анализа и бюджета по SIGHUP
+func reloadOnSignal(ctx context.Context, params *config.ParamsStore) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
 
 	for {
-		log.Println("🔍 Запускаю анализ трендов...")
-		
-		err := analyzer.AnalyzeAllItems()
-		if err != nil {
-			log.Printf("Ошибка анализа: %v", err)
-		} else {
-			log.Println("✅ Анализ трендов завершен")
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			p, err := params.Reload()
+			if err != nil {
+				i18n.Logf("log.params.reload_failed", err)
+				continue
+			}
+			i18n.Logf("log.params.reloaded", "SIGHUP")
+			i18n.Logf("log.params.active", p)
 		}
-
-		<-ticker.C
 	}
 }
-
-// Парсинг строки в float64
-func parseFloat(s string) float64 {
-	f, err := strconv.ParseFloat(s, 64)
-	if err != nil {
-		return 0
-	}
-	return f
-}
\ No newline at end of file
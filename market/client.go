@@ -7,20 +7,27 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
+	"buff-youpin-checker/config"
+	"buff-youpin-checker/metrics"
+
 	"golang.org/x/time/rate"
 )
 
 type Client struct {
-	apiKey  string
-	baseURL string
-	limiter *rate.Limiter
-	client  *http.Client
+	apiKey   string
+	baseURL  string
+	source   string
+	currency string
+	limiter  *rate.Limiter
+	client   *http.Client
 }
 
 type PriceResponse struct {
 	Success  bool        `json:"success"`
+	Error    string      `json:"error"`
 	Time     int64       `json:"time"`
 	Currency string      `json:"currency"`
 	Items    []PriceItem `json:"items"`
@@ -37,107 +44,142 @@ type ItemInfo struct {
 	History []string `json:"history"`
 }
 
-func NewClient(apiKey string) *Client {
-	// Устанавливаем лимит 4 запроса в секунду (меньше 5 для безопасности)
-	limiter := rate.NewLimiter(rate.Limit(4), 1)
-	
+func NewClient(cfg config.MarketConfig) *Client {
+	// Лимит запросов в секунду; market.csgo.com блокирует ключ при превышении 5
+	limiter := rate.NewLimiter(rate.Limit(cfg.RateLimit), 1)
+
 	return &Client{
-		apiKey:  apiKey,
-		baseURL: "https://market.csgo.com/api/v2",
-		limiter: limiter,
+		apiKey:   cfg.APIKey,
+		baseURL:  cfg.BaseURL,
+		source:   cfg.Source,
+		currency: cfg.Currency,
+		limiter:  limiter,
 		client: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: cfg.Timeout,
 		},
 	}
 }
 
-func (c *Client) makeRequest(endpoint string, params url.Values) ([]byte, error) {
+// Имя источника для истории цен
+func (c *Client) Source() string {
+	return c.source
+}
+
+// Валюта цен в ответах
+func (c *Client) Currency() string {
+	return c.currency
+}
+
+func (c *Client) makeRequest(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
 	// Ждем разрешения от rate limiter
-	ctx := context.Background()
+	waitStarted := time.Now()
 	if err := c.limiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
 	}
+	metrics.RateLimitWait.Observe(time.Since(waitStarted).Seconds())
 
-	// Добавляем обязательные параметры
+	// Добавляем обязательные параметры. API принимает ключ только в строке
+	// запроса, поэтому URL не должен попадать в ошибки и логи
 	params.Set("key", c.apiKey)
 	params.Set("v", "2")
 
 	reqURL := fmt.Sprintf("%s/%s?%s", c.baseURL, endpoint, params.Encode())
 	
-	resp, err := c.client.Get(reqURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("request error: %w", err)
+		return nil, fmt.Errorf("request error: %w", stripURL(err))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error: status %d", resp.StatusCode)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// Отмена по контексту — не сбой API
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		metrics.APIErrors.WithLabelValues("network").Inc()
+		return nil, networkError(endpoint, err)
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read error: %w", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		metrics.APIErrors.WithLabelValues("network").Inc()
+		return nil, networkError(endpoint, err)
+	}
+
+	// Тело ответа сохраняем в ошибке: в нем market API объясняет причину
+	if resp.StatusCode != http.StatusOK {
+		metrics.APIErrors.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+		return nil, statusError(resp, body)
 	}
 
 	return body, nil
 }
 
 // Получение списка цен (лучшие предложения)  
-func (c *Client) GetPrices() (*PriceResponse, error) {
+func (c *Client) GetPrices(ctx context.Context) (*PriceResponse, error) {
 	params := url.Values{}
 	
-	body, err := c.makeRequest("prices/RUB.json", params)
+	body, err := c.makeRequest(ctx, fmt.Sprintf("prices/%s.json", c.currency), params)
 	if err != nil {
 		return nil, err
 	}
 
 	var response PriceResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
+		return nil, &APIError{Kind: ErrResponse, Message: fmt.Sprintf("unmarshal error: %v", err)}
+	}
+
+	if !response.Success {
+		return nil, responseError(response.Error)
 	}
 
 	return &response, nil
 }
 
 // Получение истории продаж по hash name
-func (c *Client) GetItemHistory(hashName string) (*ItemInfo, error) {
+func (c *Client) GetItemHistory(ctx context.Context, hashName string) (*ItemInfo, error) {
 	params := url.Values{}
 	params.Set("hash_name", hashName)
 	
-	body, err := c.makeRequest("get-list-items-info", params)
+	body, err := c.makeRequest(ctx, "get-list-items-info", params)
 	if err != nil {
 		return nil, err
 	}
 
 	var response ItemInfo
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
+		return nil, &APIError{Kind: ErrResponse, Message: fmt.Sprintf("unmarshal error: %v", err)}
 	}
 
 	return &response, nil
 }
 
 // Поиск предмета по hash name
-func (c *Client) SearchItemByHashName(hashName string) ([]PriceItem, error) {
+func (c *Client) SearchItemByHashName(ctx context.Context, hashName string) ([]PriceItem, error) {
 	params := url.Values{}
 	params.Set("hash_name", hashName)
 	
-	body, err := c.makeRequest("search-item-by-hash-name", params)
+	body, err := c.makeRequest(ctx, "search-item-by-hash-name", params)
 	if err != nil {
 		return nil, err
 	}
 
 	var response struct {
 		Success bool        `json:"success"`
+		Error   string      `json:"error"`
 		Data    []PriceItem `json:"data"`
 	}
 	
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
+		return nil, &APIError{Kind: ErrResponse, Message: fmt.Sprintf("unmarshal error: %v", err)}
 	}
 
 	if !response.Success {
-		return nil, fmt.Errorf("API request failed")
+		return nil, responseError(response.Error)
 	}
 
 	return response.Data, nil
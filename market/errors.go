@@ -0,0 +1,198 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Класс ошибки market API: от него зависит, имеет ли смысл повторять запрос
+type ErrorKind int
+
+const (
+	ErrNetwork     ErrorKind = iota // соединение, таймаут
+	ErrRateLimited                  // 429, превышен лимит запросов
+	ErrServer                       // 5xx
+	ErrAuth                         // неверный или заблокированный API-ключ
+	ErrClient                       // прочие 4xx: ошибка в запросе
+	ErrResponse                     // некорректный ответ или success=false
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrNetwork:
+		return "network"
+	case ErrRateLimited:
+		return "rate_limited"
+	case ErrServer:
+		return "server"
+	case ErrAuth:
+		return "auth"
+	case ErrClient:
+		return "client"
+	default:
+		return "response"
+	}
+}
+
+// Сколько байт тела ответа сохранять в ошибке
+const maxErrorBody = 512
+
+// Ошибка запроса к market API
+type APIError struct {
+	Kind       ErrorKind
+	Status     int           // HTTP-статус; 0 для сетевых ошибок
+	Message    string        // тело ответа или текст ошибки API
+	RetryAfter time.Duration // из заголовка Retry-After для 429
+	Endpoint   string        // метод API без параметров запроса
+	Err        error         // исходная ошибка для сетевых сбоев, без URL
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Err != nil && e.Endpoint != "":
+		return fmt.Sprintf("market API %s error: %s: %v", e.Kind, e.Endpoint, e.Err)
+	case e.Err != nil:
+		return fmt.Sprintf("market API %s error: %v", e.Kind, e.Err)
+	case e.Status != 0:
+		return fmt.Sprintf("market API %s error: status %d: %s", e.Kind, e.Status, e.Message)
+	default:
+		return fmt.Sprintf("market API %s error: %s", e.Kind, e.Message)
+	}
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Временная ошибка: повтор через некоторое время может пройти
+func (e *APIError) Temporary() bool {
+	switch e.Kind {
+	case ErrNetwork, ErrRateLimited, ErrServer:
+		return true
+	}
+	return false
+}
+
+// Класс ошибки или ErrResponse, если это не ошибка API
+func KindOf(err error) ErrorKind {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Kind
+	}
+	return ErrResponse
+}
+
+// Можно ли повторить запрос после ошибки
+func IsTemporary(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Temporary()
+}
+
+// Сетевая ошибка запроса к методу endpoint
+func networkError(endpoint string, err error) *APIError {
+	return &APIError{Kind: ErrNetwork, Endpoint: endpoint, Err: stripURL(err)}
+}
+
+// *url.Error содержит полный URL вместе с API-ключом, поэтому от него
+// остается только причина
+func stripURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// Ошибка по HTTP-ответу с кодом, отличным от 200
+func statusError(resp *http.Response, body []byte) *APIError {
+	e := &APIError{Status: resp.StatusCode, Message: truncateBody(body)}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		e.Kind = ErrRateLimited
+		e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		e.Kind = ErrAuth
+	case resp.StatusCode >= 500:
+		e.Kind = ErrServer
+	case isKeyError(e.Message):
+		e.Kind = ErrAuth
+	default:
+		e.Kind = ErrClient
+	}
+	return e
+}
+
+// Ошибка по ответу с success=false
+func responseError(message string) *APIError {
+	if message == "" {
+		message = "success=false"
+	}
+	if isKeyError(message) {
+		return &APIError{Kind: ErrAuth, Message: message}
+	}
+	return &APIError{Kind: ErrResponse, Message: message}
+}
+
+// market.csgo.com сообщает о проблемах с ключом текстом вида "Bad KEY"
+func isKeyError(message string) bool {
+	return strings.Contains(strings.ToLower(message), "bad key")
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// Текст идет в TEXT-колонку Postgres, которая не принимает неверный
+// UTF-8, поэтому режем по границе символа и заменяем битые байты
+func truncateBody(body []byte) string {
+	s := strings.ToValidUTF8(strings.TrimSpace(string(body)), "\uFFFD")
+	if len(s) > maxErrorBody {
+		cut := maxErrorBody
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut] + "..."
+	}
+	return s
+}
+
+// Значение параметра key в URL и тексте ошибок
+var keyParam = regexp.MustCompile(`(?i)(\bkey=)[^&\s"']+`)
+
+// Текст ошибки, безопасный для логов, БД и сообщений администраторам:
+// значения параметра key заменены, длина ограничена
+func Redact(err error) string {
+	if err == nil {
+		return ""
+	}
+	return truncateBody([]byte(keyParam.ReplaceAllString(err.Error(), "${1}***")))
+}
+
+// Ошибка с текстом Redact; errors.Is и errors.As видят исходную ошибку
+func Redacted(err error) error {
+	if err == nil {
+		return nil
+	}
+	return redactedError{err}
+}
+
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string { return Redact(e.err) }
+func (e redactedError) Unwrap() error { return e.err }
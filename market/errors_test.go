@@ -0,0 +1,31 @@
+package market
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want func(string) bool
+	}{
+		{"ключ скрыт", errors.New(`Get "https://market.csgo.com/api/v2/prices?key=abc123&x=1": timeout`),
+			func(s string) bool { return strings.Contains(s, "key=***&x=1") && !strings.Contains(s, "abc123") }},
+		{"кириллица на границе обрезки", errors.New(strings.Repeat("я", maxErrorBody)),
+			func(s string) bool { return strings.HasSuffix(s, "я...") && len(s) <= maxErrorBody+3 }},
+		{"битые байты", errors.New("ошибка \xff\xfe"),
+			func(s string) bool { return s == "ошибка �" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redact(tt.err)
+			if !utf8.ValidString(got) || !tt.want(got) {
+				t.Errorf("Redact = %q", got)
+			}
+		})
+	}
+}
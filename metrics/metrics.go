@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "skin_checker"
+
+// Сбор цен
+var (
+	CollectionDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "collection_duration_seconds",
+		Help:      "Длительность прохода сбора цен.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600},
+	})
+	ItemsFetched = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "collection_items_fetched_total",
+		Help:      "Предметов получено от market API.",
+	})
+	ItemsStored = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "collection_items_stored_total",
+		Help:      "Цен записано в историю.",
+	})
+	ItemsUnchanged = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "collection_items_unchanged_total",
+		Help:      "Цен не записано, потому что цена и объем не изменились.",
+	})
+	ItemsFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "collection_items_failed_total",
+		Help:      "Предметов, которые не удалось записать.",
+	})
+	BreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "collection_breaker_state",
+		Help:      "Автомат защиты источника: 0 — закрыт, 1 — открыт, 2 — пробная попытка.",
+	}, []string{"source"})
+	LastSuccessfulCollection = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "collection_last_success_timestamp_seconds",
+		Help:      "Время завершения последнего успешного сбора (Unix).",
+	})
+)
+
+// Планировщик
+var Leader = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "scheduler_leader",
+	Help:      "1, если экземпляр выполняет задачи по расписанию (лидер), иначе 0.",
+})
+
+// Market API
+var (
+	APIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "market_api_errors_total",
+		Help:      "Ошибки запросов к market API по HTTP-статусу (network — ошибка соединения).",
+	}, []string{"status"})
+	RateLimitWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "market_rate_limit_wait_seconds",
+		Help:      "Ожидание разрешения rate limiter перед запросом к market API.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	})
+)
+
+// Анализ трендов
+var (
+	AnalysisDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "analysis_duration_seconds",
+		Help:      "Длительность анализа всех предметов.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	})
+	AnalyzedItems = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "analysis_items",
+		Help:      "Предметов по рекомендации в последнем завершенном анализе.",
+	}, []string{"recommendation"})
+)
+
+// Telegram-бот
+var (
+	UpdatesHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_updates_handled_total",
+		Help:      "Обработанные апдейты Telegram по типу.",
+	}, []string{"type"})
+	SendFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bot_send_failures_total",
+		Help:      "Запросы к Telegram, которые не удалось выполнить, по причине.",
+	}, []string{"reason"})
+)
+
+// Обработчик /metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,47 @@
+package optimizer
+
+import (
+	"math"
+
+	"buff-youpin-checker/config"
+)
+
+// Издержки выхода из позиции: комиссии площадки и проскальзывание —
+// сколько цены теряется, чтобы быстро продать несколько штук при малом
+// объеме предложения
+type Costs struct {
+	SaleFee     float64 // доля суммы продажи
+	WithdrawFee float64 // доля выводимых денег
+	Impact      float64 // проскальзывание Impact·√(штук/объем)
+	MaxSlippage float64 // верхняя граница; без данных об объеме — она же
+}
+
+// Издержки площадки, на которой калькулятор бюджета продает предметы
+func NewCosts(params config.BudgetConfig) Costs {
+	fees := params.Fees[params.Venue]
+	return Costs{
+		SaleFee:     fees.Sale,
+		WithdrawFee: fees.Withdraw,
+		Impact:      params.SlippageImpact,
+		MaxSlippage: params.MaxSlippage,
+	}
+}
+
+// Доля цены, теряемая при продаже units штук; volume <= 0 — объем неизвестен
+func (c Costs) Slippage(units, volume int) float64 {
+	if volume <= 0 {
+		return c.MaxSlippage
+	}
+	return math.Min(c.Impact*math.Sqrt(float64(units)/float64(volume)), c.MaxSlippage)
+}
+
+// Доля рыночной стоимости units штук, которая дойдет до счета после
+// продажи и вывода денег
+func (c Costs) Keep(units, volume int) float64 {
+	return (1 - c.SaleFee) * (1 - c.WithdrawFee) * (1 - c.Slippage(units, volume))
+}
+
+// Чистая доходность за вычетом издержек по ожидаемому росту цены gross (доли)
+func (c Costs) NetReturn(gross float64, units, volume int) float64 {
+	return (1+gross)*c.Keep(units, volume) - 1
+}
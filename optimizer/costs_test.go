@@ -0,0 +1,35 @@
+package optimizer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosts(t *testing.T) {
+	costs := Costs{SaleFee: 0.1, WithdrawFee: 0.05, Impact: 0.1, MaxSlippage: 0.03}
+
+	tests := []struct {
+		name           string
+		units, volume  int
+		gross          float64
+		slippage, keep float64
+		net            float64
+	}{
+		{"объем неизвестен", 1, 0, 0.2, 0.03, 0.9 * 0.95 * 0.97, 1.2*0.9*0.95*0.97 - 1},
+		{"малая доля объема", 4, 100, 0.2, 0.02, 0.9 * 0.95 * 0.98, 1.2*0.9*0.95*0.98 - 1},
+		{"проскальзывание ограничено сверху", 50, 100, 0, 0.03, 0.9 * 0.95 * 0.97, 0.9*0.95*0.97 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := costs.Slippage(tt.units, tt.volume); math.Abs(got-tt.slippage) > 1e-12 {
+				t.Errorf("Slippage = %v, ожидалось %v", got, tt.slippage)
+			}
+			if got := costs.Keep(tt.units, tt.volume); math.Abs(got-tt.keep) > 1e-12 {
+				t.Errorf("Keep = %v, ожидалось %v", got, tt.keep)
+			}
+			if got := costs.NetReturn(tt.gross, tt.units, tt.volume); math.Abs(got-tt.net) > 1e-12 {
+				t.Errorf("NetReturn = %v, ожидалось %v", got, tt.net)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package optimizer
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+// Меньше дневных доходностей — оценки ненадежны, предмет не рассматривается
+const MinObservations = 5
+
+// Доля, с которой выборочная ковариация сжимается к диагонали: при
+// коротких рядах внедиагональные оценки слишком шумные
+const shrinkage = 0.5
+
+// Дневные лог-доходности предметов, выровненные по дням (UTC).
+// Returns[d][i] — доходность предмета IDs[i] за день Days[d]; NaN, если
+// цены за этот или предыдущий день нет.
+type History struct {
+	IDs     []int
+	Days    []time.Time
+	Returns [][]float64
+}
+
+// Построение истории доходностей по дневным ценам; порядок предметов
+// совпадает с ids
+func NewHistory(ids []int, prices []database.DailyPrice) *History {
+	index := make(map[int]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	byDay := make(map[time.Time][]float64)
+	for _, p := range prices {
+		i, ok := index[p.ItemID]
+		if !ok {
+			continue
+		}
+		day := p.Day.UTC().Truncate(24 * time.Hour)
+		row, ok := byDay[day]
+		if !ok {
+			row = make([]float64, len(ids))
+			byDay[day] = row
+		}
+		row[i] = p.Price
+	}
+
+	days := make([]time.Time, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(a, b int) bool { return days[a].Before(days[b]) })
+
+	h := &History{IDs: ids}
+	for d := 1; d < len(days); d++ {
+		// Доходность считается только между соседними календарными днями
+		if days[d].Sub(days[d-1]) != 24*time.Hour {
+			continue
+		}
+		prev, cur := byDay[days[d-1]], byDay[days[d]]
+		row := make([]float64, len(ids))
+		for i := range ids {
+			if prev[i] > 0 && cur[i] > 0 {
+				row[i] = math.Log(cur[i] / prev[i])
+			} else {
+				row[i] = math.NaN()
+			}
+		}
+		h.Days = append(h.Days, days[d])
+		h.Returns = append(h.Returns, row)
+	}
+	return h
+}
+
+// Число дневных доходностей предмета i
+func (h *History) Observations(i int) int {
+	n := 0
+	for _, row := range h.Returns {
+		if !math.IsNaN(row[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// Ожидаемые доходности (доли) и ковариация лог-доходностей за horizon
+// дней. Ковариация считается по общим дням каждой пары и сжимается
+// к диагонали.
+func (h *History) Estimate(horizon int) ([]float64, [][]float64) {
+	n := len(h.IDs)
+	means := make([]float64, n)
+	for i := range means {
+		sum, count := 0.0, 0
+		for _, row := range h.Returns {
+			if !math.IsNaN(row[i]) {
+				sum += row[i]
+				count++
+			}
+		}
+		if count > 0 {
+			means[i] = sum / float64(count)
+		}
+	}
+
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sum, count := 0.0, 0
+			for _, row := range h.Returns {
+				if math.IsNaN(row[i]) || math.IsNaN(row[j]) {
+					continue
+				}
+				sum += (row[i] - means[i]) * (row[j] - means[j])
+				count++
+			}
+			c := 0.0
+			if count > 1 {
+				c = sum / float64(count-1) * float64(horizon)
+			}
+			if i != j {
+				c *= 1 - shrinkage
+			}
+			cov[i][j], cov[j][i] = c, c
+		}
+	}
+
+	returns := make([]float64, n)
+	for i, m := range means {
+		returns[i] = math.Expm1(m * float64(horizon))
+	}
+	return returns, cov
+}
@@ -0,0 +1,83 @@
+package optimizer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+func TestNewHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	prices := []database.DailyPrice{
+		{ItemID: 1, Day: day(1), Price: 100},
+		{ItemID: 1, Day: day(2), Price: 110},
+		{ItemID: 2, Day: day(2), Price: 50},
+		{ItemID: 1, Day: day(3), Price: 99},
+		{ItemID: 2, Day: day(3), Price: 55},
+		// Пропуск 4-го числа: доходность за 5-е не считается
+		{ItemID: 1, Day: day(5), Price: 120},
+		{ItemID: 3, Day: day(5), Price: 1}, // не в списке
+	}
+
+	h := NewHistory([]int{1, 2}, prices)
+	if len(h.Days) != 2 || !h.Days[0].Equal(day(2)) || !h.Days[1].Equal(day(3)) {
+		t.Fatalf("дни %v, ожидались 2 и 3 марта", h.Days)
+	}
+	if got := h.Returns[0][0]; math.Abs(got-math.Log(1.1)) > 1e-12 {
+		t.Errorf("доходность 1 за 2 марта = %v", got)
+	}
+	if !math.IsNaN(h.Returns[0][1]) {
+		t.Errorf("доходность 2 за 2 марта = %v, ожидался NaN", h.Returns[0][1])
+	}
+	if got := h.Returns[1][1]; math.Abs(got-math.Log(1.1)) > 1e-12 {
+		t.Errorf("доходность 2 за 3 марта = %v", got)
+	}
+	if h.Observations(0) != 2 || h.Observations(1) != 1 {
+		t.Errorf("наблюдений %d и %d, ожидалось 2 и 1", h.Observations(0), h.Observations(1))
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name    string
+		returns [][]float64
+		horizon int
+		want    []float64
+		cov     [][]float64
+	}{
+		{
+			name:    "пропуски и сжатие ковариации",
+			returns: [][]float64{{0.01, 0.02}, {0.03, nan}, {0.02, 0}},
+			horizon: 10,
+			want:    []float64{math.Expm1(0.2), math.Expm1(0.1)},
+			// Ковариация пары по дням 1 и 3: −0.0001·10, сжатая вдвое
+			cov: [][]float64{{0.001, -0.0005}, {-0.0005, 0.002}},
+		},
+		{
+			name:    "одно наблюдение",
+			returns: [][]float64{{0.05, nan}},
+			horizon: 3,
+			want:    []float64{math.Expm1(0.15), 0},
+			cov:     [][]float64{{0, 0}, {0, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &History{IDs: []int{1, 2}, Returns: tt.returns}
+			returns, cov := h.Estimate(tt.horizon)
+			for i := range tt.want {
+				if math.Abs(returns[i]-tt.want[i]) > 1e-12 {
+					t.Errorf("доходность %d = %v, ожидалось %v", i, returns[i], tt.want[i])
+				}
+				for j := range tt.cov[i] {
+					if math.Abs(cov[i][j]-tt.cov[i][j]) > 1e-12 {
+						t.Errorf("ковариация [%d][%d] = %v, ожидалось %v", i, j, cov[i][j], tt.cov[i][j])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package optimizer
+
+import (
+	"math"
+	"sort"
+)
+
+// Предмет-кандидат
+type Asset struct {
+	ID       int
+	Category string
+	Price    float64
+	MaxUnits int // ограничение по ликвидности и настройкам
+	Volume   int // последний известный объем; 0 — неизвестен
+}
+
+// Задача: максимизировать ожидаемую чистую доходность за вычетом штрафа
+// за риск w·r − λ/2·w·Σ·w, где w — доли бюджета, при ограничениях на
+// бюджет, целое число штук, число позиций и долю категорий. Чистая
+// доходность учитывает издержки продажи, которые растут с числом штук.
+type Problem struct {
+	Assets       []Asset
+	Returns      []float64   // ожидаемый рост цены за горизонт, доля
+	Covariance   [][]float64 // ковариация доходностей за горизонт
+	Budget       float64
+	RiskAversion float64            // λ: чем больше, тем осторожнее портфель
+	MaxPositions int                // не больше разных предметов
+	CategoryCaps map[string]float64 // доля бюджета на категорию; категории без доли не покупаются
+	Costs        Costs
+	MinReturn    float64 // чистая доходность каждой позиции не ниже, доля
+}
+
+type Position struct {
+	Asset       Asset
+	Units       int
+	Cost        float64
+	Return      float64 // ожидаемая доходность за горизонт после издержек, доля
+	GrossReturn float64 // ожидаемый рост цены, доля
+}
+
+type Result struct {
+	Positions      []Position
+	Cost           float64
+	ExpectedProfit float64 // после издержек
+	StdDev         float64 // стандартное отклонение прибыли за горизонт
+}
+
+// Минимальный прирост цели, при котором замена принимается; защищает
+// от циклов из-за ошибок округления
+const improvementEps = 1e-12
+
+// Решение в два этапа. Сначала жадное добавление по одной штуке: каждый
+// шаг берет предмет с наибольшим приростом целевой функции на рубль.
+// Жадный шаг не берет дорогой предмет, на который не хватает остатка
+// бюджета, даже если он выгоднее нескольких дешевых штук, поэтому затем
+// идет локальное улучшение: одна штука позиции заменяется штукой другого
+// предмета с добором остатка бюджета, пока цель растет. Результат —
+// локальный оптимум целочисленной задачи, не обязательно глобальный.
+// Решение детерминировано: равные приросты разрешаются порядком Assets.
+func Solve(p Problem) Result {
+	if p.Budget <= 0 {
+		return Result{}
+	}
+
+	s := newState(&p)
+	s.fill()
+	for {
+		best, bestValue := (*state)(nil), s.objective()+improvementEps
+		for i := range p.Assets {
+			if s.units[i] == 0 {
+				continue
+			}
+			removed := s.clone()
+			removed.remove(i)
+			for j := range p.Assets {
+				if j == i || !removed.canAdd(j) {
+					continue
+				}
+				trial := removed.clone()
+				trial.add(j)
+				trial.fill()
+				if value := trial.objective(); value > bestValue {
+					best, bestValue = trial, value
+				}
+			}
+		}
+		if best == nil {
+			break
+		}
+		s = best
+	}
+
+	units, sigmaW := s.units, s.sigmaW
+	var result Result
+	variance := 0.0
+	for i, a := range p.Assets {
+		if units[i] == 0 {
+			continue
+		}
+		cost := a.Price * float64(units[i])
+		result.Positions = append(result.Positions, Position{
+			Asset:       a,
+			Units:       units[i],
+			Cost:        cost,
+			Return:      p.Costs.NetReturn(p.Returns[i], units[i], a.Volume),
+			GrossReturn: p.Returns[i],
+		})
+		result.Cost += cost
+		result.ExpectedProfit += s.profit(i, units[i])
+		variance += cost / p.Budget * sigmaW[i]
+	}
+	result.StdDev = math.Sqrt(math.Max(variance, 0)) * p.Budget
+
+	// Крупные позиции первыми; при равной сумме — по ID
+	sort.SliceStable(result.Positions, func(a, b int) bool {
+		pa, pb := result.Positions[a], result.Positions[b]
+		if pa.Cost != pb.Cost {
+			return pa.Cost > pb.Cost
+		}
+		return pa.Asset.ID < pb.Asset.ID
+	})
+	return result
+}
+
+// Промежуточное решение: число штук и суммы, нужные для проверки
+// ограничений и прироста цели
+type state struct {
+	p             *Problem
+	units         []int
+	sigmaW        []float64 // (Σ·w)_i, обновляется при каждом шаге
+	categorySpent map[string]float64
+	spent         float64
+	positions     int
+}
+
+func newState(p *Problem) *state {
+	return &state{
+		p:             p,
+		units:         make([]int, len(p.Assets)),
+		sigmaW:        make([]float64, len(p.Assets)),
+		categorySpent: make(map[string]float64),
+	}
+}
+
+func (s *state) clone() *state {
+	c := *s
+	c.units = append([]int(nil), s.units...)
+	c.sigmaW = append([]float64(nil), s.sigmaW...)
+	c.categorySpent = make(map[string]float64, len(s.categorySpent))
+	for category, spent := range s.categorySpent {
+		c.categorySpent[category] = spent
+	}
+	return &c
+}
+
+// Ожидаемая чистая прибыль u штук предмета i
+func (s *state) profit(i, u int) float64 {
+	a := s.p.Assets[i]
+	return float64(u) * a.Price * s.p.Costs.NetReturn(s.p.Returns[i], u, a.Volume)
+}
+
+// Можно ли добавить штуку предмета i, не нарушив ограничений
+func (s *state) canAdd(i int) bool {
+	p, a := s.p, s.p.Assets[i]
+	if a.Price <= 0 || s.units[i] >= a.MaxUnits || s.spent+a.Price > p.Budget {
+		return false
+	}
+	if s.units[i] == 0 && s.positions >= p.MaxPositions {
+		return false
+	}
+	if s.categorySpent[a.Category]+a.Price > p.CategoryCaps[a.Category]*p.Budget {
+		return false
+	}
+	// Проскальзывание растет с числом штук: лишняя штука не должна
+	// опускать доходность позиции ниже порога
+	return p.Costs.NetReturn(p.Returns[i], s.units[i]+1, a.Volume) >= p.MinReturn
+}
+
+// Прирост целевой функции от еще одной штуки предмета i
+func (s *state) gain(i int) float64 {
+	p, a := s.p, s.p.Assets[i]
+	d := a.Price / p.Budget
+	return (s.profit(i, s.units[i]+1)-s.profit(i, s.units[i]))/p.Budget -
+		p.RiskAversion/2*(2*d*s.sigmaW[i]+p.Covariance[i][i]*d*d)
+}
+
+func (s *state) add(i int) {
+	s.change(i, 1)
+}
+
+func (s *state) remove(i int) {
+	s.change(i, -1)
+}
+
+func (s *state) change(i, delta int) {
+	a := s.p.Assets[i]
+	d := float64(delta) * a.Price / s.p.Budget
+	if s.units[i] == 0 {
+		s.positions++
+	}
+	s.units[i] += delta
+	if s.units[i] == 0 {
+		s.positions--
+	}
+	for j := range s.sigmaW {
+		s.sigmaW[j] += s.p.Covariance[j][i] * d
+	}
+	s.spent += float64(delta) * a.Price
+	s.categorySpent[a.Category] += float64(delta) * a.Price
+}
+
+// Жадное добавление штук, пока прирост цели положителен
+func (s *state) fill() {
+	for {
+		best, bestGain := -1, 0.0
+		for i, a := range s.p.Assets {
+			if !s.canAdd(i) {
+				continue
+			}
+			if perRouble := s.gain(i) / a.Price; perRouble > bestGain {
+				best, bestGain = i, perRouble
+			}
+		}
+		if best < 0 {
+			return
+		}
+		s.add(best)
+	}
+}
+
+// Значение цели w·r − λ/2·w·Σ·w с учетом издержек
+func (s *state) objective() float64 {
+	value := 0.0
+	for i, u := range s.units {
+		if u == 0 {
+			continue
+		}
+		w := float64(u) * s.p.Assets[i].Price / s.p.Budget
+		value += s.profit(i, u)/s.p.Budget - s.p.RiskAversion/2*w*s.sigmaW[i]
+	}
+	return value
+}
@@ -0,0 +1,236 @@
+package optimizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Задача без риска и издержек: цель равна ожидаемой прибыли
+func plainProblem(budget float64, assets []Asset, returns []float64) Problem {
+	cov := make([][]float64, len(assets))
+	for i := range cov {
+		cov[i] = make([]float64, len(assets))
+	}
+	caps := make(map[string]float64)
+	for _, a := range assets {
+		caps[a.Category] = 1
+	}
+	return Problem{
+		Assets:       assets,
+		Returns:      returns,
+		Covariance:   cov,
+		Budget:       budget,
+		MaxPositions: len(assets),
+		CategoryCaps: caps,
+		MinReturn:    -1,
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		problem func() Problem
+		want    map[int]int // штук по ID
+	}{
+		{
+			name: "нет бюджета",
+			problem: func() Problem {
+				return plainProblem(0, []Asset{{ID: 1, Category: "a", Price: 10, MaxUnits: 5}}, []float64{0.1})
+			},
+			want: map[int]int{},
+		},
+		{
+			name: "целое число штук в пределах бюджета",
+			problem: func() Problem {
+				return plainProblem(100, []Asset{{ID: 1, Category: "a", Price: 30, MaxUnits: 10}}, []float64{0.1})
+			},
+			want: map[int]int{1: 3},
+		},
+		{
+			name: "ограничение ликвидности",
+			problem: func() Problem {
+				return plainProblem(100, []Asset{{ID: 1, Category: "a", Price: 10, MaxUnits: 2}}, []float64{0.1})
+			},
+			want: map[int]int{1: 2},
+		},
+		{
+			name: "доли категорий",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{
+					{ID: 1, Category: "a", Price: 10, MaxUnits: 10},
+					{ID: 2, Category: "b", Price: 10, MaxUnits: 10},
+				}, []float64{0.2, 0.1})
+				p.CategoryCaps = map[string]float64{"a": 0.3, "b": 0.5}
+				return p
+			},
+			want: map[int]int{1: 3, 2: 5},
+		},
+		{
+			name: "категория без доли не покупается",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{
+					{ID: 1, Category: "a", Price: 10, MaxUnits: 10},
+					{ID: 2, Category: "b", Price: 10, MaxUnits: 10},
+				}, []float64{0.2, 0.1})
+				delete(p.CategoryCaps, "a")
+				return p
+			},
+			want: map[int]int{2: 10},
+		},
+		{
+			name: "число позиций",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{
+					{ID: 1, Category: "a", Price: 10, MaxUnits: 3},
+					{ID: 2, Category: "a", Price: 10, MaxUnits: 3},
+				}, []float64{0.1, 0.2})
+				p.MaxPositions = 1
+				return p
+			},
+			want: map[int]int{2: 3},
+		},
+		{
+			name: "равные предметы разрешаются порядком",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{
+					{ID: 7, Category: "a", Price: 10, MaxUnits: 3},
+					{ID: 3, Category: "a", Price: 10, MaxUnits: 3},
+				}, []float64{0.1, 0.1})
+				p.MaxPositions = 1
+				return p
+			},
+			want: map[int]int{7: 3},
+		},
+		{
+			name: "доходность ниже порога",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{{ID: 1, Category: "a", Price: 10, MaxUnits: 5}}, []float64{0.1})
+				p.MinReturn = 0.2
+				return p
+			},
+			want: map[int]int{},
+		},
+		{
+			name: "проскальзывание ограничивает число штук порогом",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{{ID: 1, Category: "a", Price: 10, MaxUnits: 10, Volume: 100}}, []float64{0.1})
+				// Чистая доходность 1.1·(1 − 0.2·√(u/100)) − 1 ≥ 0.05 при u ≤ 5
+				p.Costs = Costs{Impact: 0.2, MaxSlippage: 1}
+				p.MinReturn = 0.05
+				return p
+			},
+			want: map[int]int{1: 5},
+		},
+		{
+			name: "издержки съедают рост",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{{ID: 1, Category: "a", Price: 10, MaxUnits: 5}}, []float64{0.05})
+				p.Costs = Costs{SaleFee: 0.1}
+				return p
+			},
+			want: map[int]int{},
+		},
+		{
+			name: "дорогой предмет вместо дешевого при остатке бюджета",
+			problem: func() Problem {
+				// Жадный шаг берет B (выше доходность на рубль), после чего на A
+				// не хватает денег; замена B на A дает 30 вместо 27
+				return plainProblem(100, []Asset{
+					{ID: 1, Category: "a", Price: 60, MaxUnits: 1},
+					{ID: 2, Category: "a", Price: 45, MaxUnits: 1},
+				}, []float64{0.5, 0.6})
+			},
+			want: map[int]int{1: 1},
+		},
+		{
+			name: "риск распределяет бюджет между предметами",
+			problem: func() Problem {
+				p := plainProblem(100, []Asset{
+					{ID: 1, Category: "a", Price: 10, MaxUnits: 10},
+					{ID: 2, Category: "a", Price: 10, MaxUnits: 10},
+				}, []float64{0.1, 0.1})
+				p.Covariance = [][]float64{{0.04, 0}, {0, 0.04}}
+				p.RiskAversion = 5
+				return p
+			},
+			want: map[int]int{1: 5, 2: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.problem()
+			result := Solve(p)
+
+			got := make(map[int]int)
+			cost := 0.0
+			for _, pos := range result.Positions {
+				got[pos.Asset.ID] = pos.Units
+				cost += pos.Cost
+				if pos.Cost != pos.Asset.Price*float64(pos.Units) {
+					t.Errorf("позиция %d: стоимость %v, штук %d", pos.Asset.ID, pos.Cost, pos.Units)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("штук %v, ожидалось %v", got, tt.want)
+			}
+			if cost > p.Budget || cost != result.Cost {
+				t.Errorf("стоимость %v (итог %v), бюджет %v", cost, result.Cost, p.Budget)
+			}
+
+			// Одинаковая задача дает одинаковый результат
+			if again := Solve(tt.problem()); !reflect.DeepEqual(result, again) {
+				t.Errorf("повторное решение отличается: %+v и %+v", result, again)
+			}
+		})
+	}
+}
+
+func TestSolveRespectsConstraints(t *testing.T) {
+	assets := []Asset{
+		{ID: 1, Category: "knife", Price: 250, MaxUnits: 1, Volume: 5},
+		{ID: 2, Category: "rifle", Price: 40, MaxUnits: 4, Volume: 60},
+		{ID: 3, Category: "rifle", Price: 15, MaxUnits: 10, Volume: 0},
+		{ID: 4, Category: "pistol", Price: 7, MaxUnits: 20, Volume: 300},
+		{ID: 5, Category: "pistol", Price: 120, MaxUnits: 2, Volume: 20},
+	}
+	p := plainProblem(500, assets, []float64{0.3, 0.15, 0.2, 0.08, 0.25})
+	p.Covariance = [][]float64{
+		{0.09, 0.01, 0, 0, 0.02},
+		{0.01, 0.04, 0.01, 0, 0},
+		{0, 0.01, 0.05, 0, 0},
+		{0, 0, 0, 0.01, 0},
+		{0.02, 0, 0, 0, 0.06},
+	}
+	p.RiskAversion = 2
+	p.MaxPositions = 3
+	p.CategoryCaps = map[string]float64{"knife": 0.5, "rifle": 0.3, "pistol": 0.4}
+	p.Costs = Costs{SaleFee: 0.025, Impact: 0.1, MaxSlippage: 0.05}
+	p.MinReturn = 0.05
+
+	result := Solve(p)
+	if len(result.Positions) == 0 || len(result.Positions) > p.MaxPositions {
+		t.Fatalf("позиций %d, допустимо от 1 до %d", len(result.Positions), p.MaxPositions)
+	}
+	byCategory := make(map[string]float64)
+	for _, pos := range result.Positions {
+		if pos.Units < 1 || pos.Units > pos.Asset.MaxUnits {
+			t.Errorf("позиция %d: %d штук при пределе %d", pos.Asset.ID, pos.Units, pos.Asset.MaxUnits)
+		}
+		if pos.Return < p.MinReturn {
+			t.Errorf("позиция %d: доходность %v ниже порога", pos.Asset.ID, pos.Return)
+		}
+		byCategory[pos.Asset.Category] += pos.Cost
+	}
+	for category, spent := range byCategory {
+		if spent > p.CategoryCaps[category]*p.Budget {
+			t.Errorf("категория %s: %v при доле %v", category, spent, p.CategoryCaps[category])
+		}
+	}
+	if result.Cost > p.Budget {
+		t.Errorf("стоимость %v больше бюджета %v", result.Cost, p.Budget)
+	}
+	if result.StdDev <= 0 {
+		t.Errorf("отклонение %v при ненулевой ковариации", result.StdDev)
+	}
+}
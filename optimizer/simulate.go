@@ -0,0 +1,149 @@
+package optimizer
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// Распределение стоимости портфеля по дням горизонта, полученное
+// бутстрэпом исторических дневных доходностей. Стоимость — сумма,
+// которая останется после продажи с учетом издержек.
+type Simulation struct {
+	Cost  float64   // вложено
+	P5    []float64 // 5-й перцентиль стоимости на день 0..horizon
+	P50   []float64
+	P95   []float64
+	Final []float64 // стоимость в конце горизонта по всем траекториям, по возрастанию
+
+	LossProbability float64 // доля траекторий, закончившихся ниже Cost
+}
+
+// Перцентиль итоговой стоимости, q от 0 до 1
+func (s *Simulation) Quantile(q float64) float64 {
+	return quantile(s.Final, q)
+}
+
+// Моделирование paths траекторий на horizon дней. Каждый день траектории —
+// случайно выбранный исторический день, в котором есть доходности всех
+// предметов корзины, поэтому корреляции сохраняются. Если таких дней
+// меньше MinObservations, доходности предметов выбираются независимо.
+// Издержки costs вычитаются из стоимости каждого дня, поэтому уже в
+// день 0 она ниже вложенной суммы. Генератор инициализируется составом
+// корзины: одинаковый портфель дает одинаковый результат. nil, если
+// истории нет.
+func Simulate(h *History, positions []Position, costs Costs, horizon, paths int) *Simulation {
+	if len(positions) == 0 || horizon < 1 || paths < 1 {
+		return nil
+	}
+
+	index := make(map[int]int, len(h.IDs))
+	for i, id := range h.IDs {
+		index[id] = i
+	}
+	columns := make([]int, len(positions))
+	values := make([]float64, len(positions))
+	cost := 0.0
+	liquidation := 0.0 // стоимость при продаже сразу после покупки
+	seed := fnv.New64a()
+	for k, p := range positions {
+		i, ok := index[p.Asset.ID]
+		if !ok {
+			return nil
+		}
+		columns[k] = i
+		values[k] = p.Cost * costs.Keep(p.Units, p.Asset.Volume)
+		cost += p.Cost
+		liquidation += values[k]
+		seed.Write([]byte(strconv.Itoa(p.Asset.ID) + ":" + strconv.Itoa(p.Units) + ";"))
+	}
+
+	// Дни с полными данными по корзине и отдельные ряды каждого предмета
+	var joint [][]float64
+	own := make([][]float64, len(positions))
+	for _, row := range h.Returns {
+		complete := make([]float64, len(positions))
+		full := true
+		for k, i := range columns {
+			r := row[i]
+			if math.IsNaN(r) {
+				full = false
+				continue
+			}
+			complete[k] = r
+			own[k] = append(own[k], r)
+		}
+		if full {
+			joint = append(joint, complete)
+		}
+	}
+	independent := len(joint) < MinObservations
+	if independent {
+		for _, series := range own {
+			if len(series) == 0 {
+				return nil
+			}
+		}
+	}
+
+	rng := rand.New(rand.NewSource(int64(seed.Sum64())))
+	byDay := make([][]float64, horizon+1) // стоимость траекторий по дням
+	for d := range byDay {
+		byDay[d] = make([]float64, paths)
+	}
+
+	logValue := make([]float64, len(positions))
+	for path := 0; path < paths; path++ {
+		for k := range logValue {
+			logValue[k] = 0
+		}
+		byDay[0][path] = liquidation
+		for d := 1; d <= horizon; d++ {
+			var day []float64
+			if !independent {
+				day = joint[rng.Intn(len(joint))]
+			}
+			total := 0.0
+			for k := range positions {
+				if independent {
+					logValue[k] += own[k][rng.Intn(len(own[k]))]
+				} else {
+					logValue[k] += day[k]
+				}
+				total += values[k] * math.Exp(logValue[k])
+			}
+			byDay[d][path] = total
+		}
+	}
+
+	sim := &Simulation{
+		Cost: cost,
+		P5:   make([]float64, horizon+1),
+		P50:  make([]float64, horizon+1),
+		P95:  make([]float64, horizon+1),
+	}
+	for d, day := range byDay {
+		sort.Float64s(day)
+		sim.P5[d] = quantile(day, 0.05)
+		sim.P50[d] = quantile(day, 0.5)
+		sim.P95[d] = quantile(day, 0.95)
+	}
+	sim.Final = byDay[horizon]
+
+	losses := sort.SearchFloat64s(sim.Final, cost)
+	sim.LossProbability = float64(losses) / float64(paths)
+	return sim
+}
+
+// Перцентиль отсортированной выборки с линейной интерполяцией
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
@@ -0,0 +1,106 @@
+package optimizer
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// История с постоянной доходностью r для всех предметов
+func flatHistory(ids []int, days int, r float64) *History {
+	h := &History{IDs: ids}
+	for d := 0; d < days; d++ {
+		row := make([]float64, len(ids))
+		for i := range row {
+			row[i] = r
+		}
+		h.Returns = append(h.Returns, row)
+	}
+	return h
+}
+
+func TestSimulateFlat(t *testing.T) {
+	h := flatHistory([]int{1, 2}, 10, 0.01)
+	positions := []Position{
+		{Asset: Asset{ID: 1, Price: 30}, Units: 2, Cost: 60},
+		{Asset: Asset{ID: 2, Price: 40}, Units: 1, Cost: 40},
+	}
+	costs := Costs{SaleFee: 0.1}
+
+	sim := Simulate(h, positions, costs, 3, 50)
+	if sim == nil {
+		t.Fatal("нет результата")
+	}
+	if sim.Cost != 100 {
+		t.Errorf("вложено %v, ожидалось 100", sim.Cost)
+	}
+	for d := 0; d <= 3; d++ {
+		// День 0 — стоимость при продаже сразу после покупки
+		want := 90 * math.Exp(0.01*float64(d))
+		for _, got := range []float64{sim.P5[d], sim.P50[d], sim.P95[d]} {
+			if math.Abs(got-want) > 1e-9 {
+				t.Errorf("день %d: %v, ожидалось %v", d, got, want)
+			}
+		}
+	}
+	if sim.LossProbability != 1 {
+		t.Errorf("вероятность убытка %v, ожидалась 1", sim.LossProbability)
+	}
+	if got := sim.Quantile(0.5); math.Abs(got-90*math.Exp(0.03)) > 1e-9 {
+		t.Errorf("медиана %v", got)
+	}
+}
+
+func TestSimulateDeterministic(t *testing.T) {
+	nan := math.NaN()
+	h := &History{IDs: []int{1, 2}}
+	for d := 0; d < 30; d++ {
+		r1 := 0.02 * math.Sin(float64(d))
+		r2 := 0.03 * math.Cos(float64(d*3))
+		if d%7 == 0 {
+			r2 = nan
+		}
+		h.Returns = append(h.Returns, []float64{r1, r2})
+	}
+	positions := []Position{
+		{Asset: Asset{ID: 1, Price: 10, Volume: 50}, Units: 3, Cost: 30},
+		{Asset: Asset{ID: 2, Price: 70}, Units: 1, Cost: 70},
+	}
+	costs := Costs{SaleFee: 0.02, Impact: 0.1, MaxSlippage: 0.05}
+
+	first := Simulate(h, positions, costs, 7, 200)
+	second := Simulate(h, positions, costs, 7, 200)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("одинаковая корзина дает разные результаты")
+	}
+	for d := range first.P50 {
+		if first.P5[d] > first.P50[d] || first.P50[d] > first.P95[d] {
+			t.Errorf("день %d: перцентили не упорядочены: %v %v %v", d, first.P5[d], first.P50[d], first.P95[d])
+		}
+	}
+	if first.LossProbability < 0 || first.LossProbability > 1 {
+		t.Errorf("вероятность убытка %v", first.LossProbability)
+	}
+}
+
+func TestSimulateWithoutHistory(t *testing.T) {
+	h := flatHistory([]int{1}, 10, 0.01)
+	tests := []struct {
+		name      string
+		positions []Position
+		horizon   int
+		paths     int
+	}{
+		{"пустая корзина", nil, 5, 10},
+		{"предмета нет в истории", []Position{{Asset: Asset{ID: 2}, Units: 1, Cost: 10}}, 5, 10},
+		{"нулевой горизонт", []Position{{Asset: Asset{ID: 1}, Units: 1, Cost: 10}}, 0, 10},
+		{"нет траекторий", []Position{{Asset: Asset{ID: 1}, Units: 1, Cost: 10}}, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sim := Simulate(h, tt.positions, Costs{}, tt.horizon, tt.paths); sim != nil {
+				t.Errorf("ожидался nil, получено %+v", sim)
+			}
+		})
+	}
+}
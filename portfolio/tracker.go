@@ -0,0 +1,330 @@
+package portfolio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+var (
+	ErrUnknownItem          = errors.New("item not found")
+	ErrInsufficientQuantity = errors.New("insufficient quantity to sell")
+)
+
+type Tracker struct {
+	db *database.DB
+}
+
+// Лот - одна покупка, к которой по FIFO привязываются продажи
+type Lot struct {
+	ItemID       int       `json:"item_id"`
+	MarketName   string    `json:"market_name"`
+	Category     string    `json:"category"`
+	Venue        string    `json:"venue"`
+	BoughtAt     time.Time `json:"bought_at"`
+	Quantity     int       `json:"quantity"`      // куплено
+	OpenQuantity int       `json:"open_quantity"` // осталось на руках
+	BuyPrice     float64   `json:"buy_price"`
+	CurrentPrice float64   `json:"current_price"`
+	Unrealized   float64   `json:"unrealized"` // по открытому остатку
+	Realized     float64   `json:"realized"`   // по проданной части
+}
+
+// Доля категории в текущей стоимости портфеля
+type CategoryShare struct {
+	Category string  `json:"category"`
+	Value    float64 `json:"value"`
+	Share    float64 `json:"share"` // 0..1
+}
+
+type Summary struct {
+	Lots        []Lot           `json:"lots"`
+	CostBasis   float64         `json:"cost_basis"`   // стоимость открытых позиций по цене покупки
+	MarketValue float64         `json:"market_value"` // стоимость открытых позиций по рынку
+	Unrealized  float64         `json:"unrealized"`
+	Realized    float64         `json:"realized"`
+	Allocation  []CategoryShare `json:"allocation"`
+}
+
+type ValuePoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+func NewTracker(db *database.DB) *Tracker {
+	return &Tracker{db: db}
+}
+
+// Запись покупки или продажи. Продажа не может превышать открытый остаток
+// на дату сделки и не должна оставить без покрытия более поздние продажи.
+func (t *Tracker) Record(userID int64, itemName, side string, quantity int, price float64, venue string, executedAt time.Time) (*database.PortfolioTransaction, error) {
+	side = strings.ToUpper(side)
+	if side != "BUY" && side != "SELL" {
+		return nil, fmt.Errorf("unknown side %q", side)
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	if price < 0 {
+		return nil, fmt.Errorf("price must not be negative")
+	}
+
+	item, err := t.db.GetItemByName(strings.TrimSpace(itemName))
+	if err != nil {
+		return nil, ErrUnknownItem
+	}
+
+	tx := &database.PortfolioTransaction{
+		UserID:     userID,
+		ItemID:     item.ID,
+		MarketName: item.MarketName,
+		Category:   item.Category,
+		Side:       side,
+		Quantity:   quantity,
+		Price:      price,
+		Venue:      venue,
+		ExecutedAt: executedAt,
+	}
+	if side == "BUY" {
+		err = t.db.AddPortfolioTransaction(tx)
+	} else {
+		err = t.db.AddPortfolioTransactionChecked(tx, func(existing []database.PortfolioTransaction) error {
+			return checkSell(existing, tx)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Сводка по портфелю пользователя с оценкой по последним ценам
+func (t *Tracker) Summary(userID int64) (*Summary, error) {
+	txs, err := t.db.GetPortfolioTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	lots := buildLots(txs)
+	prices, err := t.db.GetLatestPrices(lotItemIDs(lots))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{}
+	byCategory := make(map[string]float64)
+
+	for i := range lots {
+		lot := &lots[i]
+		lot.CurrentPrice = lot.BuyPrice
+		if price, ok := prices[lot.ItemID]; ok {
+			lot.CurrentPrice = price
+		}
+
+		open := float64(lot.OpenQuantity)
+		lot.Unrealized = (lot.CurrentPrice - lot.BuyPrice) * open
+
+		summary.CostBasis += lot.BuyPrice * open
+		summary.MarketValue += lot.CurrentPrice * open
+		summary.Unrealized += lot.Unrealized
+		summary.Realized += lot.Realized
+		byCategory[lot.Category] += lot.CurrentPrice * open
+	}
+
+	for category, value := range byCategory {
+		if value <= 0 {
+			continue
+		}
+		summary.Allocation = append(summary.Allocation, CategoryShare{
+			Category: category,
+			Value:    value,
+			Share:    value / summary.MarketValue,
+		})
+	}
+	sort.Slice(summary.Allocation, func(i, j int) bool {
+		if summary.Allocation[i].Value != summary.Allocation[j].Value {
+			return summary.Allocation[i].Value > summary.Allocation[j].Value
+		}
+		return summary.Allocation[i].Category < summary.Allocation[j].Category
+	})
+
+	summary.Lots = lots
+	return summary, nil
+}
+
+// Стоимость портфеля по дням (UTC) за последние days дней
+func (t *Tracker) ValueHistory(userID int64, days int) ([]ValuePoint, error) {
+	txs, err := t.db.GetPortfolioTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	today := truncateDay(time.Now().UTC())
+	start := today.AddDate(0, 0, -days)
+	if first := truncateDay(txs[0].ExecutedAt); first.After(start) {
+		start = first
+	}
+
+	// Цена хотя бы раз в сутки записывается и без изменений (heartbeat не
+	// больше 24 часов), поэтому для цены на начало хватает предыдущего дня
+	itemIDs := lotItemIDs(buildLots(txs))
+	daily, err := t.db.GetDailyPrices(itemIDs, start.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	// Цены по дням для каждого предмета, с переносом последней известной цены вперед
+	pricesByItem := make(map[int][]database.DailyPrice)
+	for _, p := range daily {
+		pricesByItem[p.ItemID] = append(pricesByItem[p.ItemID], p)
+	}
+
+	var points []ValuePoint
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		holdings := make(map[int]int)
+		lastTradePrice := make(map[int]float64)
+		for _, tx := range txs {
+			if !tx.ExecutedAt.Before(dayEnd) {
+				break
+			}
+			if tx.Side == "BUY" {
+				holdings[tx.ItemID] += tx.Quantity
+			} else {
+				holdings[tx.ItemID] -= tx.Quantity
+			}
+			lastTradePrice[tx.ItemID] = tx.Price
+		}
+
+		value := 0.0
+		for itemID, qty := range holdings {
+			if qty <= 0 {
+				continue
+			}
+			price, ok := priceAt(pricesByItem[itemID], day)
+			if !ok {
+				price = lastTradePrice[itemID]
+			}
+			value += price * float64(qty)
+		}
+
+		points = append(points, ValuePoint{Time: day, Value: value})
+	}
+
+	return points, nil
+}
+
+// Разбор сделок на лоты: продажи списываются с самых старых покупок (FIFO)
+func buildLots(txs []database.PortfolioTransaction) []Lot {
+	var lots []Lot
+	openByItem := make(map[int][]int) // индексы лотов с открытым остатком
+
+	for _, tx := range txs {
+		if tx.Side == "BUY" {
+			lots = append(lots, Lot{
+				ItemID:       tx.ItemID,
+				MarketName:   tx.MarketName,
+				Category:     tx.Category,
+				Venue:        tx.Venue,
+				BoughtAt:     tx.ExecutedAt,
+				Quantity:     tx.Quantity,
+				OpenQuantity: tx.Quantity,
+				BuyPrice:     tx.Price,
+			})
+			openByItem[tx.ItemID] = append(openByItem[tx.ItemID], len(lots)-1)
+			continue
+		}
+
+		remaining := tx.Quantity
+		queue := openByItem[tx.ItemID]
+		for remaining > 0 && len(queue) > 0 {
+			lot := &lots[queue[0]]
+			matched := lot.OpenQuantity
+			if matched > remaining {
+				matched = remaining
+			}
+			lot.OpenQuantity -= matched
+			lot.Realized += (tx.Price - lot.BuyPrice) * float64(matched)
+			remaining -= matched
+			if lot.OpenQuantity == 0 {
+				queue = queue[1:]
+			}
+		}
+		openByItem[tx.ItemID] = queue
+	}
+
+	return lots
+}
+
+// Проверка продажи sell по сделкам с тем же предметом: при воспроизведении
+// в порядке executed_at остаток не должен уходить в минус ни на одной
+// продаже — ни на новой, ни на более поздних. Новая сделка идет после
+// записанных с тем же временем, как и в GetPortfolioTransactions.
+func checkSell(existing []database.PortfolioTransaction, sell *database.PortfolioTransaction) error {
+	qty := 0
+	inserted := false
+	apply := func(tx *database.PortfolioTransaction) error {
+		if tx.Side == "BUY" {
+			qty += tx.Quantity
+			return nil
+		}
+		qty -= tx.Quantity
+		if qty < 0 {
+			return ErrInsufficientQuantity
+		}
+		return nil
+	}
+
+	for i := range existing {
+		if !inserted && existing[i].ExecutedAt.After(sell.ExecutedAt) {
+			inserted = true
+			if err := apply(sell); err != nil {
+				return err
+			}
+		}
+		if err := apply(&existing[i]); err != nil {
+			return err
+		}
+	}
+	if !inserted {
+		return apply(sell)
+	}
+	return nil
+}
+
+func lotItemIDs(lots []Lot) []int {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, lot := range lots {
+		if !seen[lot.ItemID] {
+			seen[lot.ItemID] = true
+			ids = append(ids, lot.ItemID)
+		}
+	}
+	return ids
+}
+
+// Последняя известная цена на конец дня (точки отсортированы по дате)
+func priceAt(points []database.DailyPrice, day time.Time) (float64, bool) {
+	price, found := 0.0, false
+	for _, p := range points {
+		if p.Day.After(day) {
+			break
+		}
+		price, found = p.Price, true
+	}
+	return price, found
+}
+
+// Начало дня в UTC, как у дневных цен
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,109 @@
+package portfolio
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"buff-youpin-checker/database"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func buy(item, qty int, price float64, d int) database.PortfolioTransaction {
+	return database.PortfolioTransaction{ItemID: item, Side: "BUY", Quantity: qty, Price: price, ExecutedAt: day(d)}
+}
+
+func sell(item, qty int, price float64, d int) database.PortfolioTransaction {
+	return database.PortfolioTransaction{ItemID: item, Side: "SELL", Quantity: qty, Price: price, ExecutedAt: day(d)}
+}
+
+func TestBuildLots(t *testing.T) {
+	type lot struct {
+		item, open int
+		realized   float64
+	}
+	tests := []struct {
+		name string
+		txs  []database.PortfolioTransaction
+		want []lot
+	}{
+		{
+			name: "частичная продажа первого лота",
+			txs:  []database.PortfolioTransaction{buy(1, 3, 10, 1), buy(1, 2, 20, 2), sell(1, 2, 15, 3)},
+			want: []lot{{1, 1, 10}, {1, 2, 0}},
+		},
+		{
+			name: "продажа через несколько лотов",
+			txs:  []database.PortfolioTransaction{buy(1, 3, 10, 1), buy(1, 2, 20, 2), sell(1, 4, 25, 3)},
+			want: []lot{{1, 0, 45}, {1, 1, 5}},
+		},
+		{
+			name: "продажи разных предметов не смешиваются",
+			txs:  []database.PortfolioTransaction{buy(1, 1, 10, 1), buy(2, 2, 5, 1), sell(2, 2, 4, 2), buy(2, 1, 6, 3)},
+			want: []lot{{1, 1, 0}, {2, 0, -2}, {2, 1, 0}},
+		},
+		{
+			name: "несколько продаж одного лота",
+			txs:  []database.PortfolioTransaction{buy(1, 5, 10, 1), sell(1, 1, 12, 2), sell(1, 2, 9, 3)},
+			want: []lot{{1, 2, 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lots := buildLots(tt.txs)
+			if len(lots) != len(tt.want) {
+				t.Fatalf("лотов %d, ожидалось %d", len(lots), len(tt.want))
+			}
+			for i, w := range tt.want {
+				got := lots[i]
+				if got.ItemID != w.item || got.OpenQuantity != w.open || got.Realized != w.realized {
+					t.Errorf("лот %d: предмет %d, остаток %d, прибыль %v; ожидалось %+v",
+						i, got.ItemID, got.OpenQuantity, got.Realized, w)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSell(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []database.PortfolioTransaction
+		sell     database.PortfolioTransaction
+		wantErr  bool
+	}{
+		{"в пределах остатка", []database.PortfolioTransaction{buy(1, 3, 10, 1)}, sell(1, 3, 12, 2), false},
+		{"больше остатка", []database.PortfolioTransaction{buy(1, 3, 10, 1)}, sell(1, 4, 12, 2), true},
+		{"продажа до покупки", []database.PortfolioTransaction{buy(1, 3, 10, 5)}, sell(1, 1, 12, 2), true},
+		{
+			name:     "задним числом без покрытия более поздней продажи",
+			existing: []database.PortfolioTransaction{buy(1, 3, 10, 1), sell(1, 3, 12, 5)},
+			sell:     sell(1, 1, 11, 3),
+			wantErr:  true,
+		},
+		{
+			name:     "задним числом при докупке до поздней продажи",
+			existing: []database.PortfolioTransaction{buy(1, 3, 10, 1), buy(1, 1, 10, 4), sell(1, 3, 12, 5)},
+			sell:     sell(1, 1, 11, 3),
+			wantErr:  false,
+		},
+		{
+			name:     "в тот же день после покупки",
+			existing: []database.PortfolioTransaction{buy(1, 2, 10, 3)},
+			sell:     sell(1, 2, 11, 3),
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.sell
+			err := checkSell(tt.existing, &s)
+			if tt.wantErr != errors.Is(err, ErrInsufficientQuantity) || (!tt.wantErr && err != nil) {
+				t.Errorf("ошибка %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+		})
+	}
+}